@@ -3,12 +3,18 @@ package main
 import (
 	"Todo-app/internal/server"
 	"log"
+	_ "time/tzdata"
 )
 
 func main() {
-	log.Println("Server is starting on port 8080")
+	config, err := server.NewConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Server is starting on %s, gRPC on %s", config.BindAddr, config.GRPCBindAddr)
 
-	if err := server.Start(); err != nil {
+	if err := server.Start(config); err != nil {
 		log.Fatal(err)
 	}
 }
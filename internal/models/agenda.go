@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	DefaultUpcomingDays = 7
+	MaxAgendaDays       = 92
+)
+
+// AgendaDay holds the items due on one day in the agenda's time zone. Count
+// is the number of all matching items, Items at most the requested limit.
+type AgendaDay struct {
+	Date  string      `json:"date"`
+	Count int         `json:"count"`
+	Items []*ToDoItem `json:"items"`
+}
+
+type Agenda struct {
+	TimeZone string       `json:"time_zone"`
+	From     string       `json:"from,omitempty"`
+	To       string       `json:"to"`
+	Count    int          `json:"count"`
+	Days     []*AgendaDay `json:"days"`
+}
+
+// AgendaInput describes an agenda request. From and To are dates
+// (YYYY-MM-DD) in TimeZone and only used by date range requests, Days only by
+// the upcoming view. Done items are left out unless IncludeDone is set.
+type AgendaInput struct {
+	TimeZone    string
+	From        string
+	To          string
+	Days        int
+	IncludeDone bool
+	Limit       int
+}
+
+func (i *AgendaInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.TimeZone, validation.Required, validation.By(timeZone)),
+		validation.Field(&i.From, validation.Date(time.DateOnly)),
+		validation.Field(&i.To, validation.Date(time.DateOnly)),
+		validation.Field(&i.Days, validation.Min(0), validation.Max(MaxAgendaDays)),
+		validation.Field(&i.Limit, validation.Min(0), validation.Max(MaxPageSize)),
+	)
+}
+
+// Range returns the first and last day of a date range request. From
+// defaults to today and To to the last of DefaultUpcomingDays days from From.
+func (i *AgendaInput) Range(today time.Time) (time.Time, time.Time, error) {
+	var err error
+
+	from := today
+	if i.From != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, i.From, today.Location()); err != nil {
+			return time.Time{}, time.Time{}, validation.Errors{"from": err}
+		}
+	}
+
+	to := from.AddDate(0, 0, DefaultUpcomingDays-1)
+	if i.To != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, i.To, today.Location()); err != nil {
+			return time.Time{}, time.Time{}, validation.Errors{"to": err}
+		}
+	}
+
+	switch {
+	case to.Before(from):
+		return time.Time{}, time.Time{}, validation.Errors{"to": errors.New("must not be before from")}
+	case !to.Before(from.AddDate(0, 0, MaxAgendaDays)):
+		return time.Time{}, time.Time{}, validation.Errors{"to": errors.New("date range is too long")}
+	}
+
+	return from, to, nil
+}
+
+// AgendaQuery selects the items due in [Start, End), or before End when Start
+// is nil, grouped by day in TimeZone.
+type AgendaQuery struct {
+	Start       *time.Time
+	End         time.Time
+	TimeZone    string
+	IncludeDone bool
+	Limit       int
+}
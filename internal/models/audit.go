@@ -0,0 +1,130 @@
+package models
+
+import (
+	"reflect"
+	"time"
+)
+
+const (
+	AuditEntityList = "list"
+	AuditEntityItem = "item"
+)
+
+const (
+	AuditCreate    = "create"
+	AuditUpdate    = "update"
+	AuditDelete    = "delete"
+	AuditRestore   = "restore"
+	AuditArchive   = "archive"
+	AuditUnarchive = "unarchive"
+	AuditReorder   = "reorder"
+	AuditMove      = "move"
+)
+
+// AuditEntry is one immutable record of a change made by ActorID.
+type AuditEntry struct {
+	ID        int64                   `json:"id"`
+	ActorID   int                     `json:"actor_id"`
+	Action    string                  `json:"action"`
+	Entity    string                  `json:"entity"`
+	EntityID  int                     `json:"entity_id"`
+	ListID    int                     `json:"list_id"`
+	Changes   map[string]*FieldChange `json:"changes"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditFilter narrows the admin-wide audit query. Zero values mean no filter.
+type AuditFilter struct {
+	ActorID int
+	From    *time.Time
+	To      *time.Time
+}
+
+// ChangePosition is a place in the stream of changes, which is ordered by
+// the transaction that wrote an entry and then by the entry's id.
+type ChangePosition struct {
+	XID int64
+	ID  int64
+}
+
+// ChangePage is what a subscription reads from the stream of changes.
+// Waiting reports whether later changes are held back until older
+// transactions have finished.
+type ChangePage struct {
+	Entries []*AuditEntry
+	Next    ChangePosition
+	Waiting bool
+}
+
+// Diff returns the fields whose values differ between two snapshots. A nil
+// snapshot stands for a row that doesn't exist yet or anymore.
+func Diff(before, after map[string]interface{}) map[string]*FieldChange {
+	changes := make(map[string]*FieldChange)
+
+	for field, b := range before {
+		a, ok := after[field]
+		if !ok || !reflect.DeepEqual(a, b) {
+			changes[field] = &FieldChange{Before: b, After: a}
+		}
+	}
+
+	for field, a := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = &FieldChange{After: a}
+		}
+	}
+
+	return changes
+}
+
+// AuditFields returns a snapshot of the list as recorded in the audit log.
+func (t *ToDoList) AuditFields() map[string]interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"title":       t.Title,
+		"description": t.Description,
+		"position":    t.Position,
+	}
+}
+
+// AuditFields returns a snapshot of the item as recorded in the audit log.
+func (i *ToDoItem) AuditFields() map[string]interface{} {
+	if i == nil {
+		return nil
+	}
+
+	var due interface{}
+	if i.Due != nil {
+		due = i.Due.UTC().Format(time.RFC3339)
+	}
+
+	labels := i.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	var assignee interface{}
+	if i.AssigneeID != nil {
+		assignee = *i.AssigneeID
+	}
+
+	return map[string]interface{}{
+		"title":       i.Title,
+		"description": i.Description,
+		"done":        i.Done,
+		"due":         due,
+		"labels":      labels,
+		"priority":    i.Priority,
+		"assignee_id": assignee,
+		"position":    i.Position,
+		"list_id":     i.ListID,
+	}
+}
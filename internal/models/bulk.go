@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	// MaxBulkItems is the most items a bulk operation changes at once.
+	MaxBulkItems = 100
+	// MaxBatchOperations is the most operations a batch runs.
+	MaxBatchOperations = 50
+)
+
+// ItemSelection picks the items of a bulk operation, either by id or with a
+// filter expression.
+type ItemSelection struct {
+	IDs   []int  `json:"ids"`
+	Query string `json:"query"`
+}
+
+func (s *ItemSelection) Validate() error {
+	if (len(s.IDs) == 0) == (s.Query == "") {
+		return errors.New("exactly one of ids or query must be set")
+	}
+
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.IDs, validation.Length(0, MaxBulkItems)),
+		validation.Field(&s.Query, validation.Length(0, 1000)),
+	)
+}
+
+// BatchOperation is one request of a batch. Paths are those of the API, such
+// as /api/v1/todos/1/items/2.
+type BatchOperation struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type BatchInput struct {
+	Operations []*BatchOperation `json:"operations"`
+}
+
+var batchMethods = []interface{}{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func (i *BatchInput) Validate() error {
+	err := validation.ValidateStruct(
+		i,
+		validation.Field(&i.Operations, validation.Required, validation.Length(1, MaxBatchOperations)),
+	)
+	if err != nil {
+		return err
+	}
+
+	errs := validation.Errors{}
+	for n, op := range i.Operations {
+		if op == nil {
+			errs[fmt.Sprintf("operations[%d]", n)] = errors.New("cannot be null")
+			continue
+		}
+
+		err := validation.ValidateStruct(
+			op,
+			validation.Field(&op.Method, validation.Required, validation.In(batchMethods...)),
+			validation.Field(&op.Path, validation.Required, validation.By(batchPath)),
+		)
+		if err != nil {
+			errs[fmt.Sprintf("operations[%d]", n)] = err
+		}
+	}
+
+	return errs.Filter()
+}
+
+// batchPrefixes are the roots of the API paths a batch may call, the current
+// version and the deprecated unversioned one.
+var batchPrefixes = []string{"/api/v1/", "/private/"}
+
+// batchPath accepts the paths of API routes other than batches and streams.
+// The checks see the path as the router does, so it has to be plain: no
+// escapes, dot segments or repeated slashes that would resolve to another
+// route.
+func batchPath(value interface{}) error {
+	u, err := url.Parse(value.(string))
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "" || u.Host != "" || u.Opaque != "" {
+		return errors.New("must be a path, not a URL")
+	}
+
+	clean := path.Clean(u.Path)
+	if strings.HasSuffix(u.Path, "/") && clean != "/" {
+		clean += "/"
+	}
+	if u.EscapedPath() != u.Path || clean != u.Path {
+		return errors.New("must be a plain path without escapes or dot segments")
+	}
+
+	for _, prefix := range batchPrefixes {
+		if !strings.HasPrefix(clean, prefix) {
+			continue
+		}
+		if strings.HasPrefix(clean, prefix+"batch") {
+			return errors.New("batches can't be nested")
+		}
+		if strings.HasPrefix(clean, prefix+"events") {
+			return errors.New("event streams can't be batched")
+		}
+		return nil
+	}
+
+	return errors.New("must be an API path under /api/v1/")
+}
+
+// BatchResult is the response to one operation of a batch.
+type BatchResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
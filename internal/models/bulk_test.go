@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestBatchPath(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/api/v1/todos/", true},
+		{"/api/v1/todos/3/items/7", true},
+		{"/api/v1/items/?q=due%3Ctoday", true},
+		{"/private/todos/", true},
+		{"/api/v1/batch", false},
+		{"/private/batch", false},
+		{"/api/v1/events", false},
+		{"/api/v1/%62atch", false},
+		{"/api/v1/%65vents", false},
+		{"/api/v1/todos%2F..%2Fbatch", false},
+		{"/api/v1/todos/../batch", false},
+		{"/api/v1/./events", false},
+		{"/api/v1//batch", false},
+		{"/api/v1/todos/..", false},
+		{"/api/v2/../v1/batch", false},
+		{"/users", false},
+		{"http://example.com/api/v1/todos/", false},
+		{"//example.com/api/v1/todos/", false},
+	}
+
+	for _, tt := range tests {
+		err := batchPath(tt.path)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("batchPath(%q) = %v, want ok = %v", tt.path, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// Domain events name what happened to a list or an item. Every audit log
+// entry is turned into one or more of them.
+const (
+	EventListCreated    = "ListCreated"
+	EventListUpdated    = "ListUpdated"
+	EventListDeleted    = "ListDeleted"
+	EventListRestored   = "ListRestored"
+	EventListArchived   = "ListArchived"
+	EventListUnarchived = "ListUnarchived"
+	EventListReordered  = "ListReordered"
+	EventItemCreated    = "ItemCreated"
+	EventItemUpdated    = "ItemUpdated"
+	EventItemCompleted  = "ItemCompleted"
+	EventItemReopened   = "ItemReopened"
+	EventItemDeleted    = "ItemDeleted"
+	EventItemRestored   = "ItemRestored"
+	EventItemReordered  = "ItemReordered"
+	EventItemMoved      = "ItemMoved"
+)
+
+var eventTypes = map[string]map[string]string{
+	AuditEntityList: {
+		AuditCreate:    EventListCreated,
+		AuditUpdate:    EventListUpdated,
+		AuditDelete:    EventListDeleted,
+		AuditRestore:   EventListRestored,
+		AuditArchive:   EventListArchived,
+		AuditUnarchive: EventListUnarchived,
+		AuditReorder:   EventListReordered,
+	},
+	AuditEntityItem: {
+		AuditCreate:  EventItemCreated,
+		AuditUpdate:  EventItemUpdated,
+		AuditDelete:  EventItemDeleted,
+		AuditRestore: EventItemRestored,
+		AuditReorder: EventItemReordered,
+		AuditMove:    EventItemMoved,
+	},
+}
+
+// webhookEvents names the webhook event, <entity>.<action>, of every event
+// type that stands for a whole audit log entry.
+var webhookEvents = func() map[string]string {
+	names := make(map[string]string)
+	for entity, actions := range eventTypes {
+		for action, eventType := range actions {
+			names[eventType] = entity + "." + action
+		}
+	}
+	return names
+}()
+
+// WebhookEvent returns the webhook event of an event type, or "" for the
+// events that only follow from another, such as ItemCompleted.
+func WebhookEvent(eventType string) string {
+	return webhookEvents[eventType]
+}
+
+// DomainEvent is a change as published to the subscribers of the outbox.
+// Events are delivered at least once; ID tells repeated deliveries apart.
+type DomainEvent struct {
+	ID        int64                   `json:"id"`
+	Type      string                  `json:"type"`
+	ActorID   int                     `json:"actor_id"`
+	ListID    int                     `json:"list_id"`
+	EntityID  int                     `json:"entity_id"`
+	AuditID   int64                   `json:"audit_id"`
+	Changes   map[string]*FieldChange `json:"changes"`
+	Attempts  int                     `json:"attempts"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// EventsOf returns the domain events of an audit log entry. An update that
+// marks an item done or not done is also an ItemCompleted or ItemReopened.
+func EventsOf(entry *AuditEntry) []*DomainEvent {
+	eventType, ok := eventTypes[entry.Entity][entry.Action]
+	if !ok {
+		return nil
+	}
+
+	events := []*DomainEvent{entry.event(eventType)}
+
+	if done, ok := entry.Changes["done"]; ok && entry.Entity == AuditEntityItem && entry.Action == AuditUpdate {
+		if after, _ := done.After.(bool); after {
+			events = append(events, entry.event(EventItemCompleted))
+		} else {
+			events = append(events, entry.event(EventItemReopened))
+		}
+	}
+
+	return events
+}
+
+func (e *AuditEntry) event(eventType string) *DomainEvent {
+	return &DomainEvent{
+		Type:     eventType,
+		ActorID:  e.ActorID,
+		ListID:   e.ListID,
+		EntityID: e.EntityID,
+		AuditID:  e.ID,
+		Changes:  e.Changes,
+	}
+}
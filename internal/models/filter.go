@@ -0,0 +1,322 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields of the item filter language.
+const (
+	FilterText     = "text"
+	FilterTitle    = "title"
+	FilterDone     = "done"
+	FilterDue      = "due"
+	FilterLabel    = "label"
+	FilterPriority = "priority"
+	FilterAssignee = "assignee"
+	FilterList     = "list"
+	filterSort     = "sort"
+)
+
+const (
+	MaxPriority    = 3
+	maxFilterTerms = 20
+)
+
+var priorityNames = map[string]int{"none": 0, "low": 1, "medium": 2, "high": 3}
+
+// filterOps lists the operators every field accepts. Longer operators come
+// first so that "<=" isn't read as "<".
+var (
+	filterOps   = []string{"<=", ">=", "!=", ":", "=", "<", ">"}
+	equalityOps = map[string]bool{":": true}
+	orderingOps = map[string]bool{":": true, "<": true, "<=": true, ">": true, ">=": true}
+	fieldOps    = map[string]map[string]bool{
+		FilterTitle:    equalityOps,
+		FilterDone:     equalityOps,
+		FilterDue:      orderingOps,
+		FilterLabel:    equalityOps,
+		FilterPriority: orderingOps,
+		FilterAssignee: equalityOps,
+		FilterList:     equalityOps,
+		filterSort:     equalityOps,
+	}
+)
+
+// ItemSorts are the fields items can be sorted by.
+var ItemSorts = map[string]bool{"due": true, "priority": true, "title": true}
+
+// FilterError reports a malformed filter expression.
+type FilterError struct {
+	Pos  int
+	Term string
+	Msg  string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter: %s in %q at position %d", e.Msg, e.Term, e.Pos)
+}
+
+// FilterTerm is one condition of an item filter. Value is a string, bool,
+// int or time.Time depending on Field, or nil for "none". When Day is set,
+// Value is the start of a whole day and the condition covers that day.
+type FilterTerm struct {
+	Field  string
+	Op     string
+	Value  interface{}
+	Day    bool
+	Negate bool
+}
+
+// ItemFilter is a parsed filter expression such as
+// `done:false due<2026-11-01 label:urgent assignee:me sort:-priority`.
+// All terms must match.
+type ItemFilter struct {
+	Terms []*FilterTerm
+	Sort  string
+	Desc  bool
+}
+
+// ParseItemFilter parses a filter expression. "me" refers to userId and
+// relative dates such as "today" or "+7d" are resolved against now, in now's
+// time zone, so saved expressions keep their meaning over time.
+func ParseItemFilter(query string, userId int, now time.Time) (*ItemFilter, error) {
+	tokens, err := splitFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) > maxFilterTerms {
+		return nil, &FilterError{Pos: tokens[maxFilterTerms].pos, Term: tokens[maxFilterTerms].text, Msg: fmt.Sprintf("more than %d terms", maxFilterTerms)}
+	}
+
+	f := &ItemFilter{Terms: make([]*FilterTerm, 0, len(tokens))}
+	p := &filterParser{userId: userId, now: now}
+
+	for _, tok := range tokens {
+		term, err := p.term(tok.text)
+		if err != nil {
+			return nil, &FilterError{Pos: tok.pos, Term: tok.text, Msg: err.Error()}
+		}
+
+		if term.Field != filterSort {
+			f.Terms = append(f.Terms, term)
+			continue
+		}
+
+		if f.Sort != "" {
+			return nil, &FilterError{Pos: tok.pos, Term: tok.text, Msg: "sort given more than once"}
+		}
+		f.Sort, f.Desc = term.Value.(string), term.Negate
+	}
+
+	return f, nil
+}
+
+type filterToken struct {
+	text string
+	pos  int
+}
+
+// splitFilter splits a query at whitespace outside of double quotes.
+func splitFilter(query string) ([]filterToken, error) {
+	tokens := make([]filterToken, 0)
+	start, quoted := -1, false
+
+	for i, c := range query {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			if start < 0 {
+				start = i
+			}
+		case !quoted && (c == ' ' || c == '\t' || c == '\n'):
+			if start >= 0 {
+				tokens = append(tokens, filterToken{text: query[start:i], pos: start})
+				start = -1
+			}
+		case start < 0:
+			start = i
+		}
+	}
+
+	if quoted {
+		return nil, &FilterError{Pos: start, Term: query[start:], Msg: "unterminated quote"}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, filterToken{text: query[start:], pos: start})
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	userId int
+	now    time.Time
+}
+
+func (p *filterParser) term(text string) (*FilterTerm, error) {
+	term := &FilterTerm{}
+	if strings.HasPrefix(text, "-") {
+		term.Negate, text = true, text[1:]
+	}
+
+	n := 0
+	for n < len(text) && (text[n] >= 'a' && text[n] <= 'z' || text[n] == '_') {
+		n++
+	}
+
+	for _, op := range filterOps {
+		if n > 0 && strings.HasPrefix(text[n:], op) {
+			term.Field, term.Op = text[:n], op
+			break
+		}
+	}
+
+	if term.Op == "" {
+		term.Field, term.Op, term.Value = FilterText, ":", unquote(text)
+		if term.Value == "" {
+			return nil, fmt.Errorf("empty term")
+		}
+		return term, nil
+	}
+
+	ops, ok := fieldOps[term.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", term.Field)
+	}
+
+	value := unquote(text[n+len(term.Op):])
+	if value == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch term.Op {
+	case "=":
+		term.Op = ":"
+	case "!=":
+		term.Op, term.Negate = ":", !term.Negate
+	}
+
+	if !ops[term.Op] {
+		return nil, fmt.Errorf("operator %q not supported for %s", term.Op, term.Field)
+	}
+
+	var err error
+	switch term.Field {
+	case FilterTitle:
+		term.Value = value
+	case FilterDone:
+		term.Value, err = strconv.ParseBool(value)
+	case FilterDue:
+		err = p.due(term, value)
+	case FilterLabel:
+		if value != "none" {
+			term.Value = value
+		}
+	case FilterPriority:
+		term.Value, err = priority(value)
+	case FilterAssignee:
+		term.Value, err = p.assignee(value)
+	case FilterList:
+		term.Value, err = strconv.Atoi(value)
+	case filterSort:
+		if term.Negate {
+			return nil, fmt.Errorf("sort can't be negated, use sort:-field")
+		}
+		term.Negate = strings.HasPrefix(value, "-")
+		term.Value = strings.TrimPrefix(value, "-")
+		if !ItemSorts[term.Value.(string)] {
+			return nil, fmt.Errorf("can't sort by %q", term.Value)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q", term.Field, value)
+	}
+
+	return term, nil
+}
+
+// due accepts "none", a date (YYYY-MM-DD), "yesterday", "today", "tomorrow",
+// a number of days relative to today ("+7d", "-1d") or an RFC 3339 time.
+func (p *filterParser) due(term *FilterTerm, value string) error {
+	if value == "none" {
+		if term.Op != ":" {
+			return fmt.Errorf("none can only be compared with :")
+		}
+		return nil
+	}
+
+	y, m, d := p.now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, p.now.Location())
+	term.Day = true
+
+	switch value {
+	case "yesterday":
+		term.Value = today.AddDate(0, 0, -1)
+		return nil
+	case "today":
+		term.Value = today
+		return nil
+	case "tomorrow":
+		term.Value = today.AddDate(0, 0, 1)
+		return nil
+	}
+
+	if len(value) > 2 && (value[0] == '+' || value[0] == '-') && strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(value[1 : len(value)-1])
+		if err != nil {
+			return err
+		}
+		if value[0] == '-' {
+			days = -days
+		}
+		term.Value = today.AddDate(0, 0, days)
+		return nil
+	}
+
+	if day, err := time.ParseInLocation(time.DateOnly, value, p.now.Location()); err == nil {
+		term.Value = day
+		return nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	term.Value, term.Day = at, false
+	return err
+}
+
+func (p *filterParser) assignee(value string) (interface{}, error) {
+	switch value {
+	case "none":
+		return nil, nil
+	case "me":
+		return p.userId, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func priority(value string) (int, error) {
+	if n, ok := priorityNames[value]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > MaxPriority {
+		return 0, fmt.Errorf("priority out of range")
+	}
+
+	return n, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
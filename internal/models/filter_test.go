@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var filterNow = time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+
+func filterDay(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseItemFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *ItemFilter
+	}{
+		{"", &ItemFilter{Terms: []*FilterTerm{}}},
+		{"milk", &ItemFilter{Terms: []*FilterTerm{{Field: FilterText, Op: ":", Value: "milk"}}}},
+		{`"oat milk"`, &ItemFilter{Terms: []*FilterTerm{{Field: FilterText, Op: ":", Value: "oat milk"}}}},
+		{`title:"weekly report"  done:false`, &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterTitle, Op: ":", Value: "weekly report"},
+			{Field: FilterDone, Op: ":", Value: false},
+		}}},
+		{"done=true label:none", &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterDone, Op: ":", Value: true},
+			{Field: FilterLabel, Op: ":", Value: nil},
+		}}},
+		{"priority>=medium priority<3", &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterPriority, Op: ">=", Value: 2},
+			{Field: FilterPriority, Op: "<", Value: 3},
+		}}},
+		{"assignee:me list:7 assignee:none", &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterAssignee, Op: ":", Value: 42},
+			{Field: FilterList, Op: ":", Value: 7},
+			{Field: FilterAssignee, Op: ":", Value: nil},
+		}}},
+		{"due<2026-11-01 due:today due>=+7d due<=-1d due:yesterday due>tomorrow", &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterDue, Op: "<", Value: filterDay(2026, 11, 1), Day: true},
+			{Field: FilterDue, Op: ":", Value: filterDay(2026, 3, 14), Day: true},
+			{Field: FilterDue, Op: ">=", Value: filterDay(2026, 3, 21), Day: true},
+			{Field: FilterDue, Op: "<=", Value: filterDay(2026, 3, 13), Day: true},
+			{Field: FilterDue, Op: ":", Value: filterDay(2026, 3, 13), Day: true},
+			{Field: FilterDue, Op: ">", Value: filterDay(2026, 3, 15), Day: true},
+		}}},
+		{"due<2026-03-14T12:00:00Z due:none", &ItemFilter{Terms: []*FilterTerm{
+			{Field: FilterDue, Op: "<", Value: time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)},
+			{Field: FilterDue, Op: ":", Value: nil},
+		}}},
+		{"sort:-priority done:false", &ItemFilter{
+			Terms: []*FilterTerm{{Field: FilterDone, Op: ":", Value: false}},
+			Sort:  "priority",
+			Desc:  true,
+		}},
+		{"sort:title", &ItemFilter{Terms: []*FilterTerm{}, Sort: "title"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseItemFilter(tt.query, 42, filterNow)
+			if err != nil {
+				t.Fatalf("ParseItemFilter(%q): %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseItemFilter(%q) = %s, want %s", tt.query, describeFilter(got), describeFilter(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseItemFilterNegation(t *testing.T) {
+	tests := []struct {
+		query  string
+		negate bool
+	}{
+		{"label:urgent", false},
+		{"-label:urgent", true},
+		{"label!=urgent", true},
+		{"-label!=urgent", false},
+		{"-milk", true},
+		{"-due<today", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := ParseItemFilter(tt.query, 42, filterNow)
+			if err != nil {
+				t.Fatalf("ParseItemFilter(%q): %v", tt.query, err)
+			}
+
+			if term := f.Terms[0]; term.Negate != tt.negate {
+				t.Errorf("ParseItemFilter(%q) negates = %v, want %v", tt.query, term.Negate, tt.negate)
+			}
+		})
+	}
+}
+
+func TestParseItemFilterErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		term  string
+		msg   string
+	}{
+		{`done:false title:"weekly`, 11, `title:"weekly`, "unterminated quote"},
+		{"done:maybe", 0, "done:maybe", `invalid done value "maybe"`},
+		{"milk color:red", 5, "color:red", `unknown field "color"`},
+		{"label:", 0, "label:", "missing value"},
+		{`""`, 0, `""`, "empty term"},
+		{"  label<urgent", 2, "label<urgent", `operator "<" not supported for label`},
+		{"done:true due>none", 10, "due>none", `invalid due value "none"`},
+		{"priority:4", 0, "priority:4", `invalid priority value "4"`},
+		{"priority>urgent", 0, "priority>urgent", `invalid priority value "urgent"`},
+		{"assignee:someone", 0, "assignee:someone", `invalid assignee value "someone"`},
+		{"due:+xd", 0, "due:+xd", `invalid due value "+xd"`},
+		{"list:seven", 0, "list:seven", `invalid list value "seven"`},
+		{"sort:due sort:title", 9, "sort:title", "sort given more than once"},
+		{"sort:label", 0, "sort:label", `can't sort by "label"`},
+		{"-sort:due", 0, "-sort:due", "sort can't be negated, use sort:-field"},
+		{strings.Repeat("a ", maxFilterTerms) + "b", 2 * maxFilterTerms, "b", "more than 20 terms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseItemFilter(tt.query, 42, filterNow)
+
+			var fe *FilterError
+			if !errors.As(err, &fe) {
+				t.Fatalf("ParseItemFilter(%q) error = %v, want a *FilterError", tt.query, err)
+			}
+			if fe.Pos != tt.pos || fe.Term != tt.term || fe.Msg != tt.msg {
+				t.Errorf("ParseItemFilter(%q) error = %+v, want {Pos:%d Term:%s Msg:%s}", tt.query, *fe, tt.pos, tt.term, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseItemFilterTimeZone(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	f, err := ParseItemFilter("due:today due:2026-03-20", 42, filterNow.In(loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := time.Date(2026, 3, 15, 0, 0, 0, 0, loc); !f.Terms[0].Value.(time.Time).Equal(want) {
+		t.Errorf("today = %v, want %v", f.Terms[0].Value, want)
+	}
+	if want := time.Date(2026, 3, 20, 0, 0, 0, 0, loc); !f.Terms[1].Value.(time.Time).Equal(want) {
+		t.Errorf("2026-03-20 = %v, want %v", f.Terms[1].Value, want)
+	}
+}
+
+func describeFilter(f *ItemFilter) string {
+	terms := make([]string, 0, len(f.Terms))
+	for _, term := range f.Terms {
+		terms = append(terms, fmt.Sprintf("%+v", *term))
+	}
+
+	return fmt.Sprintf("{Terms:[%s] Sort:%s Desc:%v}", strings.Join(terms, " "), f.Sort, f.Desc)
+}
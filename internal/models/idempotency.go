@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// IdempotencyRecord is what is stored of a request made with an
+// Idempotency-Key: a fingerprint of the request and, once it has completed,
+// its response.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Status      int
+	Headers     map[string]string
+	Body        []byte
+	CompletedAt *time.Time
+}
+
+type IdempotencyKeyInput struct {
+	Key string `json:"key"`
+}
+
+func (i *IdempotencyKeyInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Key, validation.Required, validation.Length(1, 255)),
+	)
+}
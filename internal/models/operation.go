@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const maxUndoCount = 20
+
+// Operation is one step on a user's undo stack: the audit entries written by
+// a single user action.
+type Operation struct {
+	ID        int64         `json:"id"`
+	Entries   []*AuditEntry `json:"entries"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type UndoInput struct {
+	Count int `json:"count"`
+}
+
+func (i *UndoInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Count, validation.Min(0), validation.Max(maxUndoCount)),
+	)
+}
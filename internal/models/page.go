@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ErrInvalidCursor is a cursor that can't be decoded or doesn't belong to the
+// collection it is used with.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a row of an ordered collection by its sort key and id.
+// Clients only ever see it encoded.
+type Cursor struct {
+	Key string `json:"k,omitempty"`
+	ID  int64  `json:"i"`
+}
+
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// PageRequest asks for the rows right after or right before a cursor, or for
+// the first page when neither is set.
+type PageRequest struct {
+	Limit  int
+	After  *Cursor
+	Before *Cursor
+}
+
+func (p *PageRequest) Validate() error {
+	if p.After != nil && p.Before != nil {
+		return errors.New("after and before can't be combined")
+	}
+
+	return validation.ValidateStruct(
+		p,
+		validation.Field(&p.Limit, validation.Min(0), validation.Max(MaxPageSize)),
+	)
+}
+
+// Size returns the number of rows to return.
+func (p *PageRequest) Size() int {
+	if p == nil || p.Limit == 0 {
+		return DefaultPageSize
+	}
+
+	return p.Limit
+}
+
+// PageInfo holds the cursors of the neighbouring pages, nil when there is none.
+type PageInfo struct {
+	Next *Cursor
+	Prev *Cursor
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var errPatchNotObject = errors.New("merge patch must be a JSON object")
+
+// ParseListMergePatch turns an RFC 7396 JSON merge patch of a list into an
+// update of the fields it names. A null description clears it; the title
+// can't be removed.
+func ParseListMergePatch(data []byte) (*UpdateListInput, error) {
+	fields, err := patchFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &UpdateListInput{}
+	for name, value := range fields {
+		null := string(value) == "null"
+
+		switch name {
+		case "title":
+			if null {
+				return nil, fmt.Errorf("title can't be removed")
+			}
+			err = json.Unmarshal(value, &input.Title)
+		case "description":
+			input.Description = new(string)
+			if !null {
+				err = json.Unmarshal(value, input.Description)
+			}
+		default:
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return input, nil
+}
+
+// ParseItemMergePatch turns an RFC 7396 JSON merge patch of an item into an
+// update of the fields it names. null resets a field to its default: no due
+// date, no assignee, no labels, priority 0 and an empty description. The
+// title and done can't be removed.
+func ParseItemMergePatch(data []byte) (*UpdateItemInput, error) {
+	fields, err := patchFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &UpdateItemInput{}
+	for name, value := range fields {
+		null := string(value) == "null"
+
+		switch name {
+		case "title", "done":
+			if null {
+				return nil, fmt.Errorf("%s can't be removed", name)
+			}
+			if name == "title" {
+				err = json.Unmarshal(value, &input.Title)
+			} else {
+				err = json.Unmarshal(value, &input.Done)
+			}
+		case "description":
+			input.Description = new(string)
+			if !null {
+				err = json.Unmarshal(value, input.Description)
+			}
+		case "due":
+			input.ClearDue = null
+			if !null {
+				err = json.Unmarshal(value, &input.Due)
+			}
+		case "labels":
+			input.Labels = &[]string{}
+			if !null {
+				err = json.Unmarshal(value, input.Labels)
+			}
+		case "priority":
+			input.Priority = new(int)
+			if !null {
+				err = json.Unmarshal(value, input.Priority)
+			}
+		case "assignee_id":
+			input.ClearAssignee = null
+			if !null {
+				err = json.Unmarshal(value, &input.AssigneeID)
+			}
+		default:
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return input, nil
+}
+
+func patchFields(data []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, errPatchNotObject
+	}
+
+	return fields, nil
+}
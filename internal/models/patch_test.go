@@ -0,0 +1,123 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func stringPtr(s string) *string { return &s }
+func intPtr(n int) *int          { return &n }
+func boolPtr(b bool) *bool       { return &b }
+
+func TestParseListMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  *UpdateListInput
+	}{
+		{"empty", `{}`, &UpdateListInput{}},
+		{"title only", `{"title": "Groceries"}`, &UpdateListInput{Title: stringPtr("Groceries")}},
+		{"description", `{"description": "for the weekend"}`, &UpdateListInput{Description: stringPtr("for the weekend")}},
+		{"null description clears it", `{"description": null}`, &UpdateListInput{Description: stringPtr("")}},
+		{"null with whitespace", "{\"description\" :\n null }", &UpdateListInput{Description: stringPtr("")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListMergePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParseListMergePatch(%s): %v", tt.patch, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseListMergePatch(%s) = %+v, want %+v", tt.patch, got, tt.want)
+			}
+			if empty := tt.patch == `{}`; got.Empty() != empty {
+				t.Errorf("ParseListMergePatch(%s).Empty() = %v, want %v", tt.patch, !empty, empty)
+			}
+		})
+	}
+}
+
+func TestParseItemMergePatch(t *testing.T) {
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		patch string
+		want  *UpdateItemInput
+	}{
+		{"empty", `{}`, &UpdateItemInput{}},
+		{"set fields", `{"title": "Buy milk", "done": true, "due": "2026-11-01T09:00:00Z", "labels": ["home"], "priority": 2, "assignee_id": 7}`,
+			&UpdateItemInput{Title: stringPtr("Buy milk"), Done: boolPtr(true), Due: &due, Labels: &[]string{"home"}, Priority: intPtr(2), AssigneeID: intPtr(7)}},
+		{"done false is kept", `{"done": false}`, &UpdateItemInput{Done: boolPtr(false)}},
+		{"null resets to defaults", `{"description": null, "due": null, "labels": null, "priority": null, "assignee_id": null}`,
+			&UpdateItemInput{Description: stringPtr(""), Labels: &[]string{}, Priority: intPtr(0), ClearDue: true, ClearAssignee: true}},
+		{"absent fields are left alone", `{"priority": 0}`, &UpdateItemInput{Priority: intPtr(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseItemMergePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParseItemMergePatch(%s): %v", tt.patch, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseItemMergePatch(%s) = %+v, want %+v", tt.patch, got, tt.want)
+			}
+			if empty := tt.patch == `{}`; got.Empty() != empty {
+				t.Errorf("ParseItemMergePatch(%s).Empty() = %v, want %v", tt.patch, !empty, empty)
+			}
+		})
+	}
+}
+
+func TestParseMergePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"array", `[{"title": "x"}]`, errPatchNotObject.Error()},
+		{"string", `"title"`, errPatchNotObject.Error()},
+		{"number", `1`, errPatchNotObject.Error()},
+		{"null", `null`, errPatchNotObject.Error()},
+		{"empty body", ``, errPatchNotObject.Error()},
+		{"malformed", `{"title": `, errPatchNotObject.Error()},
+		{"unknown field", `{"title": "x", "color": "red"}`, `unknown field "color"`},
+		{"read-only field", `{"id": 3}`, `unknown field "id"`},
+		{"title removed", `{"title": null}`, "title can't be removed"},
+		{"wrong type", `{"title": 5}`, "invalid title: json: cannot unmarshal number into Go value of type string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseListMergePatch([]byte(tt.patch)); err == nil || err.Error() != tt.want {
+				t.Errorf("ParseListMergePatch(%s) error = %v, want %q", tt.patch, err, tt.want)
+			}
+			if _, err := ParseItemMergePatch([]byte(tt.patch)); err == nil || err.Error() != tt.want {
+				t.Errorf("ParseItemMergePatch(%s) error = %v, want %q", tt.patch, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseItemMergePatchErrors(t *testing.T) {
+	tests := []struct {
+		patch string
+		want  string
+	}{
+		{`{"done": null}`, "done can't be removed"},
+		{`{"done": "yes"}`, "invalid done: json: cannot unmarshal string into Go value of type bool"},
+		{`{"due": "tomorrow"}`, "invalid due: parsing time"},
+		{`{"labels": "home"}`, "invalid labels: json: cannot unmarshal string into Go value of type []string"},
+		{`{"priority": 1.5}`, "invalid priority: json: cannot unmarshal number 1.5 into Go value of type int"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseItemMergePatch([]byte(tt.patch)); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ParseItemMergePatch(%s) error = %v, want %q...", tt.patch, err, tt.want)
+		}
+	}
+}
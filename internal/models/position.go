@@ -0,0 +1,25 @@
+package models
+
+import "errors"
+
+type PositionInput struct {
+	Before *int `json:"before"`
+	After  *int `json:"after"`
+}
+
+func (i PositionInput) Validate() error {
+	if (i.Before == nil) == (i.After == nil) {
+		return errors.New("exactly one of before or after must be set")
+	}
+
+	return nil
+}
+
+// Sibling returns the id of the item or list the position is relative to.
+func (i PositionInput) Sibling() int {
+	if i.Before != nil {
+		return *i.Before
+	}
+
+	return *i.After
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	SearchList = "list"
+	SearchItem = "item"
+)
+
+// SearchResult is a list or an item matching a full-text search. Title and
+// Snippet are HTML: the text is escaped and the matching words are wrapped
+// in <mark> tags.
+type SearchResult struct {
+	Type    string  `json:"type"`
+	ID      int     `json:"id"`
+	ListID  int     `json:"list_id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Rank    float32 `json:"rank"`
+}
+
+type SearchInput struct {
+	Query string
+}
+
+func (i *SearchInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Query, validation.Required, validation.Length(1, 255)),
+	)
+}
@@ -0,0 +1,30 @@
+package models
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+const maxSmartListQuery = 1000
+
+// SmartList is a saved item filter. Its items are found by evaluating Query
+// for the user reading it, so "assignee:me" or "due:today" always refer to
+// that user and to the current day.
+type SmartList struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Title  string `json:"title"`
+	Query  string `json:"query"`
+	Shared bool   `json:"shared"`
+}
+
+type SmartListInput struct {
+	Title  string `json:"title"`
+	Query  string `json:"query"`
+	Shared bool   `json:"shared"`
+}
+
+func (i *SmartListInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Title, validation.Required, validation.Length(2, 100)),
+		validation.Field(&i.Query, validation.Required, validation.Length(1, maxSmartListQuery)),
+	)
+}
@@ -0,0 +1,319 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+const (
+	// ResolveLastWriterWins settles a conflicting field in favour of the
+	// side that changed it last.
+	ResolveLastWriterWins = "last_writer_wins"
+	// ResolveReport keeps the server's value of a conflicting field and
+	// reports the conflict for the client to settle.
+	ResolveReport = "report"
+)
+
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusFailed   = "failed"
+)
+
+const (
+	SyncWinnerClient = "client"
+	SyncWinnerServer = "server"
+)
+
+const (
+	// MaxSyncMutations is the most mutations one push carries.
+	MaxSyncMutations = 100
+)
+
+// The kinds of entity a sync sends, in the order it sends them.
+const (
+	SyncKindList = iota
+	SyncKindItem
+)
+
+var errInvalidSyncToken = errors.New("invalid sync token")
+
+// SyncToken is how far a client has synced. Every change made by a
+// transaction below From has been sent; Issued is when From was reached. While
+// a sync is in progress, Until is the transaction it stops at, Started when
+// that was decided and Kind and ID are the last entity sent. Times are in
+// Unix seconds.
+type SyncToken struct {
+	From    int64 `json:"f"`
+	Issued  int64 `json:"t,omitempty"`
+	Until   int64 `json:"u,omitempty"`
+	Started int64 `json:"s,omitempty"`
+	Kind    int   `json:"k,omitempty"`
+	ID      int   `json:"i,omitempty"`
+}
+
+func (t *SyncToken) Encode() string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeSyncToken(s string) (*SyncToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidSyncToken
+	}
+
+	t := &SyncToken{}
+	if err := json.Unmarshal(b, t); err != nil || t.From < 0 || t.Until < 0 || t.Until != 0 && t.Until < t.From {
+		return nil, errInvalidSyncToken
+	}
+
+	return t, nil
+}
+
+// Full reports whether the token starts from scratch, in which case no
+// tombstones are sent.
+func (t *SyncToken) Full() bool {
+	return t.From == 0
+}
+
+// SyncKey names a list or an item that changed since a token.
+type SyncKey struct {
+	Kind int
+	ID   int
+}
+
+func (k *SyncKey) Entity() string {
+	if k.Kind == SyncKindList {
+		return AuditEntityList
+	}
+	return AuditEntityItem
+}
+
+func (k *SyncKey) IsList() bool {
+	return k.Kind == SyncKindList
+}
+
+// SyncChange is the state of a list or an item, or its tombstone when the
+// user can't see it anymore.
+type SyncChange struct {
+	Entity  string    `json:"entity"`
+	ID      int       `json:"id"`
+	Deleted bool      `json:"deleted"`
+	List    *ToDoList `json:"list,omitempty"`
+	Item    *ToDoItem `json:"item,omitempty"`
+}
+
+// SyncPage is one page of the changes since a token. Lists come before items.
+// Token continues the sync while More is set and is where the next sync
+// starts once it isn't.
+type SyncPage struct {
+	Changes []*SyncChange `json:"changes"`
+	Token   string        `json:"token"`
+	More    bool          `json:"more"`
+}
+
+// SyncMutation is one change a client made while offline. Set is a merge
+// patch of the fields it changed, or of the fields of a new entity, and Base
+// the values those fields had before. Items created in a list created by the
+// same push name its mutation in ListRef.
+type SyncMutation struct {
+	ID        string          `json:"id"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id,omitempty"`
+	ListID    int             `json:"list_id,omitempty"`
+	ListRef   string          `json:"list_ref,omitempty"`
+	Set       json.RawMessage `json:"set,omitempty"`
+	Base      json.RawMessage `json:"base,omitempty"`
+	Version   *int            `json:"version,omitempty"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// SyncInput is a push of offline mutations, applied in order.
+type SyncInput struct {
+	Resolve   string          `json:"resolve"`
+	Mutations []*SyncMutation `json:"mutations"`
+}
+
+func (i *SyncInput) Validate() error {
+	if i.Resolve == "" {
+		i.Resolve = ResolveLastWriterWins
+	}
+
+	err := validation.ValidateStruct(
+		i,
+		validation.Field(&i.Resolve, validation.In(ResolveLastWriterWins, ResolveReport)),
+		validation.Field(&i.Mutations, validation.Required, validation.Length(1, MaxSyncMutations)),
+	)
+	if err != nil {
+		return err
+	}
+
+	errs := validation.Errors{}
+	seen := make(map[string]bool, len(i.Mutations))
+	for n, m := range i.Mutations {
+		name := fmt.Sprintf("mutations[%d]", n)
+		if m == nil {
+			errs[name] = errors.New("cannot be null")
+			continue
+		}
+
+		if err := m.validate(); err != nil {
+			errs[name] = err
+			continue
+		}
+
+		if seen[m.ID] {
+			errs[name] = fmt.Errorf("id %q is used twice", m.ID)
+		}
+		seen[m.ID] = true
+	}
+
+	return errs.Filter()
+}
+
+func (m *SyncMutation) validate() error {
+	err := validation.ValidateStruct(
+		m,
+		validation.Field(&m.ID, validation.Required, validation.Length(1, 100)),
+		validation.Field(&m.Action, validation.Required, validation.In(SyncCreate, SyncUpdate, SyncDelete)),
+		validation.Field(&m.Entity, validation.Required, validation.In(AuditEntityList, AuditEntityItem)),
+		validation.Field(&m.ChangedAt, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case m.Action == SyncCreate && m.EntityID != 0:
+		return errors.New("entity_id can't be set on a create")
+	case m.Action != SyncCreate && m.EntityID == 0:
+		return errors.New("entity_id is required")
+	case m.Action == SyncCreate && m.Entity == AuditEntityItem && (m.ListID == 0) == (m.ListRef == ""):
+		return errors.New("exactly one of list_id or list_ref must be set")
+	case (m.Action != SyncCreate || m.Entity != AuditEntityItem) && (m.ListID != 0 || m.ListRef != ""):
+		return errors.New("list_id and list_ref are only for new items")
+	case m.Action != SyncDelete && len(m.Set) == 0:
+		return errors.New("set is required")
+	case m.Action == SyncDelete && len(m.Set) != 0:
+		return errors.New("set can't be given for a delete")
+	case m.Action != SyncUpdate && len(m.Base) != 0:
+		return errors.New("base is only for updates")
+	}
+
+	return nil
+}
+
+// SyncConflict is a field changed both by the client and, since the client's
+// base, on the server. Winner is the side whose value was kept.
+type SyncConflict struct {
+	Field  string      `json:"field"`
+	Base   interface{} `json:"base"`
+	Client interface{} `json:"client"`
+	Server interface{} `json:"server"`
+	Winner string      `json:"winner"`
+}
+
+// SyncError is why a mutation failed, with the code and message the API
+// would answer with.
+type SyncError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// SyncResult is the outcome of one mutation, with the state of the entity
+// after it unless it was deleted.
+type SyncResult struct {
+	ID        string          `json:"id"`
+	Status    string          `json:"status"`
+	EntityID  int             `json:"entity_id,omitempty"`
+	List      *ToDoList       `json:"list,omitempty"`
+	Item      *ToDoItem       `json:"item,omitempty"`
+	Conflicts []*SyncConflict `json:"conflicts,omitempty"`
+	Error     *SyncError      `json:"error,omitempty"`
+}
+
+// PatchKeys returns the fields a merge patch names.
+func PatchKeys(data []byte) ([]string, error) {
+	fields, err := patchFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for name := range fields {
+		keys = append(keys, name)
+	}
+
+	return keys, nil
+}
+
+// FilterPatch returns the merge patch with only the given fields.
+func FilterPatch(data []byte, keep map[string]bool) ([]byte, error) {
+	fields, err := patchFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range fields {
+		if !keep[name] {
+			delete(fields, name)
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// Apply returns a copy of the list with the update made to it.
+func (t *ToDoList) Apply(input *UpdateListInput) *ToDoList {
+	list := *t
+	if input.Title != nil {
+		list.Title = *input.Title
+	}
+	if input.Description != nil {
+		list.Description = *input.Description
+	}
+
+	return &list
+}
+
+// Apply returns a copy of the item with the update made to it.
+func (i *ToDoItem) Apply(input *UpdateItemInput) *ToDoItem {
+	item := *i
+	if input.Title != nil {
+		item.Title = *input.Title
+	}
+	if input.Description != nil {
+		item.Description = *input.Description
+	}
+	if input.Done != nil {
+		item.Done = *input.Done
+	}
+	if input.Due != nil || input.ClearDue {
+		item.Due = input.Due
+	}
+	if input.Labels != nil {
+		item.Labels = *input.Labels
+	}
+	if input.Priority != nil {
+		item.Priority = *input.Priority
+	}
+	if input.AssigneeID != nil || input.ClearAssignee {
+		item.AssigneeID = input.AssigneeID
+	}
+
+	return &item
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type ListTemplate struct {
+	ID          int             `json:"id"`
+	UserID      int             `json:"user_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Shared      bool            `json:"shared"`
+	Items       []*TemplateItem `json:"items,omitempty"`
+}
+
+// TemplateItem is an item blueprint. DueOffset is the number of seconds
+// between the template anchor and the item's due date.
+type TemplateItem struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DueOffset   *int64   `json:"due_offset,omitempty"`
+	Labels      []string `json:"labels"`
+	Position    string   `json:"-"`
+}
+
+// TemplateInput saves a list as a template. Due offsets are taken relative to
+// Anchor, which defaults to the earliest due date in the list.
+type TemplateInput struct {
+	Title  string     `json:"title"`
+	Shared bool       `json:"shared"`
+	Anchor *time.Time `json:"anchor"`
+}
+
+func (i *TemplateInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Title, validation.Length(2, 100)),
+	)
+}
+
+type InstantiateInput struct {
+	Title  string    `json:"title"`
+	Anchor time.Time `json:"anchor"`
+}
+
+func (i *InstantiateInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Title, validation.Length(2, 100)),
+		validation.Field(&i.Anchor, validation.Required),
+	)
+}
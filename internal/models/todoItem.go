@@ -1,24 +1,68 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 type ToDoItem struct {
-	ID          int
-	Title       string
-	Description string
-	Done        bool
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Due         *time.Time `json:"due"`
+	Labels      []string   `json:"labels"`
+	Priority    int        `json:"priority"`
+	AssigneeID  *int       `json:"assignee_id"`
+	Version     int        `json:"version"`
+	Position    string     `json:"position"`
+	ListID      int        `json:"list_id"`
+}
+
+// ItemPage is one page of the items of a list.
+type ItemPage struct {
+	Items []*ToDoItem
+	Info  *PageInfo
 }
 
+// UpdateItemInput changes the fields that are set and leaves the others
+// alone. Due and AssigneeID can't be set to null through a pointer, so
+// ClearDue and ClearAssignee remove them.
 type UpdateItemInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title         *string    `json:"title"`
+	Description   *string    `json:"description"`
+	Done          *bool      `json:"done"`
+	Due           *time.Time `json:"due"`
+	Labels        *[]string  `json:"labels"`
+	Priority      *int       `json:"priority"`
+	AssigneeID    *int       `json:"assignee_id"`
+	ClearDue      bool       `json:"-"`
+	ClearAssignee bool       `json:"-"`
+	Version       *int       `json:"-"`
+}
+
+// Empty reports whether the update names no field, as an empty merge patch
+// does.
+func (i UpdateItemInput) Empty() bool {
+	return i.Title == nil && i.Description == nil && i.Done == nil && i.Due == nil && i.Labels == nil &&
+		i.Priority == nil && i.AssigneeID == nil && !i.ClearDue && !i.ClearAssignee
 }
 
 func (i UpdateItemInput) Validate() error {
-	if i.Title == nil && i.Description == nil && i.Done == nil {
-		return errors.New("update structure has no values")
+	if i.Empty() {
+		return ErrNoValues
+	}
+
+	if i.ClearDue && i.Due != nil || i.ClearAssignee && i.AssigneeID != nil {
+		return errors.New("a field can't be both set and cleared")
 	}
 
-	return nil
+	return validation.ValidateStruct(
+		&i,
+		validation.Field(&i.Title, validation.NilOrNotEmpty, validation.Length(1, 255)),
+		validation.Field(&i.Description, validation.Length(0, 255)),
+		validation.Field(&i.Priority, validation.Min(0), validation.Max(MaxPriority)),
+	)
 }
@@ -6,9 +6,11 @@ import (
 )
 
 type ToDoList struct {
-	ID          int
-	Title       string
-	Description string
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     int    `json:"version"`
+	Position    string `json:"position"`
 }
 
 func (t *ToDoList) Validate() error {
@@ -18,15 +20,28 @@ func (t *ToDoList) Validate() error {
 	)
 }
 
+// ErrNoValues is returned for an update that doesn't change anything.
+var ErrNoValues = errors.New("update structure has no values")
+
 type UpdateListInput struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
+	Version     *int    `json:"-"`
+}
+
+// Empty reports whether the update names no field, as an empty merge patch
+// does.
+func (i UpdateListInput) Empty() bool {
+	return i.Title == nil && i.Description == nil
 }
 
 func (i UpdateListInput) Validate() error {
-	if i.Title == nil && i.Description == nil {
-		return errors.New("update structure has no values")
+	if i.Empty() {
+		return ErrNoValues
 	}
 
-	return nil
+	return validation.ValidateStruct(
+		&i,
+		validation.Field(&i.Title, validation.NilOrNotEmpty, validation.Length(2, 100)),
+	)
 }
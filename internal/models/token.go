@@ -0,0 +1,51 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// tokenPrefix marks the secrets of API tokens, so that leaked ones are easy
+// to recognize.
+const tokenPrefix = "todo_"
+
+// APIToken is a credential of the gRPC API. Only a hash of its secret is
+// stored; the secret itself is shown once, when the token is created.
+type APIToken struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TokenInput struct {
+	Name string `json:"name"`
+}
+
+func (i *TokenInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Name, validation.Required, validation.Length(1, 100)),
+	)
+}
+
+// NewTokenSecret returns a new random secret for an API token.
+func NewTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hash under which the secret of a token is stored.
+// The secrets are random, so a fast hash is enough.
+func HashToken(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
@@ -0,0 +1,24 @@
+package models
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// TransferInput describes items being moved or copied into another list. The
+// items are given by id or, with Query, by a filter expression.
+type TransferInput struct {
+	ItemIDs []int  `json:"ids"`
+	Query   string `json:"query"`
+	ListID  int    `json:"list_id"`
+}
+
+func (i *TransferInput) Validate() error {
+	if err := validation.ValidateStruct(i, validation.Field(&i.ListID, validation.Required)); err != nil {
+		return err
+	}
+
+	return i.Selection().Validate()
+}
+
+// Selection returns the items the transfer applies to.
+func (i *TransferInput) Selection() *ItemSelection {
+	return &ItemSelection{IDs: i.ItemIDs, Query: i.Query}
+}
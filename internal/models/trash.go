@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type TrashedList struct {
+	*ToDoList
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TrashedItem struct {
+	*ToDoItem
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -12,6 +12,8 @@ type User struct {
 	Email             string `json:"email"`
 	Password          string `json:"password,omitempty"`
 	EncryptedPassword string `json:"-"`
+	IsAdmin           bool   `json:"is_admin"`
+	TimeZone          string `json:"time_zone"`
 }
 
 func (u *User) Validate() error {
@@ -20,6 +22,18 @@ func (u *User) Validate() error {
 		validation.Field(&u.Name, validation.Required, validation.Length(2, 30)),
 		validation.Field(&u.Email, validation.Required, is.Email),
 		validation.Field(&u.Password, validation.By(requiredIf(u.EncryptedPassword == "")), validation.Length(6, 100)),
+		validation.Field(&u.TimeZone, validation.By(timeZone)),
+	)
+}
+
+type TimeZoneInput struct {
+	TimeZone string `json:"time_zone"`
+}
+
+func (i *TimeZoneInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.TimeZone, validation.Required, validation.By(timeZone)),
 	)
 }
 
@@ -33,6 +47,10 @@ func encryptString(s string) (string, error) {
 }
 
 func (u *User) BeforeCreate() error {
+	if u.TimeZone == "" {
+		u.TimeZone = "UTC"
+	}
+
 	if len(u.Password) > 0 {
 		encoded, err := encryptString(u.Password)
 		if err != nil {
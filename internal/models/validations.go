@@ -1,6 +1,11 @@
 package models
 
-import validation "github.com/go-ozzo/ozzo-validation"
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 func requiredIf(cond bool) validation.RuleFunc {
 	return func(value interface{}) error {
@@ -10,3 +15,18 @@ func requiredIf(cond bool) validation.RuleFunc {
 		return nil
 	}
 }
+
+// timeZone accepts an empty string or an IANA time zone name that Postgres
+// knows as well, which rules out Go's "Local".
+func timeZone(value interface{}) error {
+	name, _ := value.(string)
+	if name == "" {
+		return nil
+	}
+
+	if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+		return errors.New("unknown time zone")
+	}
+
+	return nil
+}
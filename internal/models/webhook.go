@@ -0,0 +1,194 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// webhookSecretPrefix marks the signing secrets of webhooks.
+const webhookSecretPrefix = "whsec_"
+
+// WebhookEvents are the events a webhook can subscribe to, named
+// <entity>.<action> after the audit log entries they carry.
+var WebhookEvents = []interface{}{
+	"list.create", "list.update", "list.delete", "list.restore", "list.archive", "list.unarchive", "list.reorder",
+	"item.create", "item.update", "item.delete", "item.restore", "item.reorder", "item.move",
+}
+
+// Webhook sends the events of one list, or with no list of every list, to a
+// URL. Only admins register webhooks for the whole workspace. The secret
+// signing the deliveries is shown once, when the webhook is created.
+type Webhook struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	ListID     *int       `json:"list_id"`
+	URL        string     `json:"url"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Secret     string     `json:"secret,omitempty"`
+}
+
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	ListID *int     `json:"list_id"`
+	Events []string `json:"events"`
+}
+
+func (i *WebhookInput) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.URL, validation.Required, validation.Length(1, 2048), validation.By(webhookURL)),
+		validation.Field(&i.Events, validation.Required, validation.Each(validation.In(WebhookEvents...))),
+	)
+}
+
+// UpdateWebhookInput changes the fields that are set. Setting Active to true
+// enables a disabled webhook again and clears its failures.
+type UpdateWebhookInput struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+func (i *UpdateWebhookInput) Validate() error {
+	if i.URL == nil && i.Events == nil && i.Active == nil {
+		return ErrNoValues
+	}
+
+	errs := validation.Errors{
+		"url": validation.Validate(i.URL, validation.NilOrNotEmpty, validation.Length(1, 2048), validation.By(webhookURL)),
+	}
+	if i.Events != nil {
+		errs["events"] = validation.Validate(*i.Events, validation.Required, validation.Each(validation.In(WebhookEvents...)))
+	}
+
+	return errs.Filter()
+}
+
+func webhookURL(value interface{}) error {
+	s, ok := value.(string)
+	if p, isPtr := value.(*string); isPtr && p != nil {
+		s, ok = *p, true
+	}
+	if !ok || s == "" {
+		return nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if addr, err := netip.ParseAddr(host); err == nil && !WebhookAddressAllowed(addr) {
+		return ErrWebhookAddress
+	}
+	if !strings.Contains(host, ".") && !strings.Contains(host, ":") {
+		return ErrWebhookAddress
+	}
+	for _, suffix := range internalDomains {
+		if strings.HasSuffix("."+host, suffix) {
+			return ErrWebhookAddress
+		}
+	}
+
+	return nil
+}
+
+// internalDomains are the special-use domains that name hosts inside a
+// network. Single-label names are refused as well.
+var internalDomains = []string{".localhost", ".local", ".internal", ".home.arpa"}
+
+// ErrWebhookAddress rejects webhook URLs that point into the server's own
+// network.
+var ErrWebhookAddress = errors.New("must point to a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip doesn't
+// count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// WebhookAddressAllowed reports whether deliveries may be sent to addr: it
+// must not be a loopback, link-local, private, unspecified or multicast
+// address.
+func WebhookAddressAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// NewWebhookSecret returns a new random secret for signing deliveries.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// SignWebhook returns the signature header of a delivery body sent at the
+// given time: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">.
+func SignWebhook(secret string, at time.Time, body []byte) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// WebhookDelivery is one event queued for a webhook and the outcome of its
+// latest attempt.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int        `json:"webhook_id"`
+	AuditID        int64      `json:"audit_id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status"`
+	Error          *string    `json:"error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+// WebhookJob is a delivery claimed for sending, with where to send it and
+// the change it carries.
+type WebhookJob struct {
+	Delivery *WebhookDelivery
+	URL      string
+	Secret   string
+	Change   *AuditEntry
+}
+
+// WebhookPayload is the body of a delivery. It is the same for every attempt.
+type WebhookPayload struct {
+	ID        int64       `json:"id"`
+	WebhookID int         `json:"webhook_id"`
+	Event     string      `json:"event"`
+	Change    *AuditEntry `json:"change"`
+}
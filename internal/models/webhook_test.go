@@ -0,0 +1,99 @@
+package models
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestWebhookURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/todo", true},
+		{"http://203.0.113.7:8080/hook", true},
+		{"https://[2001:db8::1]/hook", true},
+		{"ftp://hooks.example.com/todo", false},
+		{"/relative", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://172.16.3.4/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://100.64.0.1/hook", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://[fd00::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://localhost:8000/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://metadata/computeMetadata", false},
+		{"http://printer.local/hook", false},
+		{"http://db.internal./hook", false},
+	}
+
+	for _, tt := range tests {
+		err := webhookURL(tt.url)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("webhookURL(%q) = %v, want ok = %v", tt.url, err, tt.ok)
+		}
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":          true,
+		"2606:4700::1111":  true,
+		"127.0.0.53":       false,
+		"10.1.2.3":         false,
+		"169.254.0.1":      false,
+		"224.0.0.1":        false,
+		"::":               false,
+		"::ffff:10.0.0.1":  false,
+		"::ffff:1.1.1.1":   true,
+		"100.127.255.255":  false,
+		"255.255.255.255":  false,
+		"fe80::abcd:1234":  false,
+		"fc00::1":          false,
+		"ff02::1":          false,
+		"192.0.2.1":        true,
+		"100.128.0.1":      true,
+		"172.32.0.1":       true,
+		"192.169.0.1":      true,
+		"11.0.0.1":         true,
+		"2001:db8:ffff::1": true,
+	}
+
+	for addr, want := range tests {
+		if got := WebhookAddressAllowed(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("WebhookAddressAllowed(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestWebhookEvent(t *testing.T) {
+	// every event a webhook can subscribe to has to come out of the outbox
+	named := make(map[string]bool)
+	for _, name := range webhookEvents {
+		named[name] = true
+	}
+	for _, event := range WebhookEvents {
+		if !named[event.(string)] {
+			t.Errorf("no domain event is sent to %s webhooks", event)
+		}
+	}
+
+	tests := map[string]string{
+		EventListCreated:   "list.create",
+		EventItemUpdated:   "item.update",
+		EventItemMoved:     "item.move",
+		EventItemCompleted: "",
+		EventItemReopened:  "",
+	}
+	for eventType, want := range tests {
+		if got := WebhookEvent(eventType); got != want {
+			t.Errorf("WebhookEvent(%s) = %q, want %q", eventType, got, want)
+		}
+	}
+}
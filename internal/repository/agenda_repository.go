@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type AgendaPostgres struct {
+	db DB
+}
+
+func NewAgendaPostgres(db DB) *AgendaPostgres {
+	return &AgendaPostgres{db: db}
+}
+
+// Get returns the days that have items due in the queried span, in order.
+// Every day carries the number of its items but at most query.Limit items,
+// earliest first. Items of trashed or archived lists are left out, and an item
+// in several lists is listed and counted once, with the first of them.
+func (r *AgendaPostgres) Get(ctx context.Context, userId int, query *models.AgendaQuery) ([]*models.AgendaDay, error) {
+	days := make([]*models.AgendaDay, 0)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT d.id, d.title, d.description, d.done, d.due, d.labels, d.priority, d.assignee_id, d.version, d.position, d.list_id, d.day, d.total
+	FROM (
+		SELECT ti.*, (ti.due AT TIME ZONE $2)::date AS day,
+		count(*) OVER w AS total,
+		row_number() OVER (w ORDER BY ti.due, ti.id) AS n
+		FROM (
+			SELECT DISTINCT ON (ti.id) ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id
+			FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+			INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+			WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND tl.archived_at IS NULL
+			AND ($3::timestamptz IS NULL OR ti.due >= $3) AND ti.due < $4 AND ($5 OR NOT ti.done)
+			ORDER BY ti.id, li.list_id
+		) ti
+		WINDOW w AS (PARTITION BY (ti.due AT TIME ZONE $2)::date)
+	) d
+	WHERE d.n <= $6
+	ORDER BY d.day, d.n`, userId, query.TimeZone, query.Start, query.End, query.IncludeDone, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var day *models.AgendaDay
+	for rows.Next() {
+		var item models.ToDoItem
+		var date time.Time
+		var total int
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version,
+			&item.Position, &item.ListID, &date, &total); err != nil {
+			return nil, err
+		}
+
+		if d := date.Format(time.DateOnly); day == nil || day.Date != d {
+			day = &models.AgendaDay{Date: d, Count: total, Items: make([]*models.ToDoItem, 0)}
+			days = append(days, day)
+		}
+		day.Items = append(day.Items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AuditPostgres struct {
+	db DB
+}
+
+func NewAuditPostgres(db DB) *AuditPostgres {
+	return &AuditPostgres{db: db}
+}
+
+func (r *AuditPostgres) Record(ctx context.Context, entry *models.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, `INSERT INTO audit_log (actor_id, action, entity, entity_id, list_id, changes)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		entry.ActorID, entry.Action, entry.Entity, entry.EntityID, entry.ListID, changes,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+var auditKeyset = keyset{id: "a.id", desc: true}
+
+// GetByList returns the history of a list and of every item that was in it,
+// newest first.
+func (r *AuditPostgres) GetByList(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	cond, order, args, err := auditKeyset.clause(page, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`SELECT a.id, a.actor_id, a.action, a.entity, a.entity_id, a.list_id, a.changes, a.created_at FROM audit_log a
+	WHERE a.list_id = $1 AND EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = a.list_id AND ul.user_id = $2)
+	AND %s %s`, cond, order)
+
+	return r.page(ctx, page, query, append([]interface{}{listId, userId}, args...)...)
+}
+
+// GetByItem returns the history of an item, newest first, limited to the
+// lists the user can access.
+func (r *AuditPostgres) GetByItem(ctx context.Context, userId, itemId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	cond, order, args, err := auditKeyset.clause(page, 4)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`SELECT a.id, a.actor_id, a.action, a.entity, a.entity_id, a.list_id, a.changes, a.created_at FROM audit_log a
+	WHERE a.entity = $1 AND a.entity_id = $2 AND EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = a.list_id AND ul.user_id = $3)
+	AND %s %s`, cond, order)
+
+	return r.page(ctx, page, query, append([]interface{}{models.AuditEntityItem, itemId, userId}, args...)...)
+}
+
+func (r *AuditPostgres) Find(ctx context.Context, filter *models.AuditFilter, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	argId := 1
+
+	if filter.ActorID != 0 {
+		conditions = append(conditions, fmt.Sprintf("a.actor_id = $%d", argId))
+		args = append(args, filter.ActorID)
+		argId++
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, fmt.Sprintf("a.created_at >= $%d", argId))
+		args = append(args, *filter.From)
+		argId++
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, fmt.Sprintf("a.created_at < $%d", argId))
+		args = append(args, *filter.To)
+		argId++
+	}
+
+	cond, order, pageArgs, err := auditKeyset.clause(page, argId)
+	if err != nil {
+		return nil, nil, err
+	}
+	conditions = append(conditions, cond)
+	args = append(args, pageArgs...)
+
+	query := fmt.Sprintf(`SELECT a.id, a.actor_id, a.action, a.entity, a.entity_id, a.list_id, a.changes, a.created_at FROM audit_log a
+	WHERE %s %s`, strings.Join(conditions, " AND "), order)
+
+	return r.page(ctx, page, query, args...)
+}
+
+// GetChanges returns up to limit entries after the given position, in the
+// order of the stream, of the lists the user can access or, if listId isn't
+// 0, of one of them. Only entries of transactions older than the oldest one
+// still running are returned: everything below that horizon has committed or
+// never will, so no entry can turn up before the position later on.
+func (r *AuditPostgres) GetChanges(ctx context.Context, userId, listId int, after models.ChangePosition, limit int) (*models.ChangePage, error) {
+	var horizon int64
+	if err := r.db.QueryRowContext(ctx, "SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&horizon); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT a.id, a.actor_id, a.action, a.entity, a.entity_id, a.list_id, a.changes, a.created_at, a.xid
+	FROM audit_log a
+	WHERE (a.xid, a.id) > ($1, $2) AND a.xid < $3 AND ($4 = 0 OR a.list_id = $4)
+	AND EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = a.list_id AND ul.user_id = $5)
+	ORDER BY a.xid, a.id LIMIT $6`, after.XID, after.ID, horizon, listId, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &models.ChangePage{Entries: make([]*models.AuditEntry, 0), Next: after}
+	for rows.Next() {
+		var xid int64
+		entry, err := scanAuditEntry(rows, &xid)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Entries = append(page.Entries, entry)
+		page.Next = models.ChangePosition{XID: xid, ID: entry.ID}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Entries) == limit {
+		return page, nil
+	}
+
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM audit_log a
+	WHERE a.xid >= $1 AND (a.xid, a.id) > ($2, $3) AND ($4 = 0 OR a.list_id = $4)
+	AND EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = a.list_id AND ul.user_id = $5))`,
+		horizon, page.Next.XID, page.Next.ID, listId, userId).Scan(&page.Waiting)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// LastChanged returns when each field of a list or an item was last changed,
+// according to its history.
+func (r *AuditPostgres) LastChanged(ctx context.Context, entity string, entityId int) (map[string]time.Time, error) {
+	changed := make(map[string]time.Time)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT f.field, max(a.created_at) FROM audit_log a, jsonb_object_keys(a.changes) AS f(field)
+	WHERE a.entity = $1 AND a.entity_id = $2 GROUP BY f.field`, entity, entityId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var field string
+		var at time.Time
+		if err := rows.Scan(&field, &at); err != nil {
+			return nil, err
+		}
+		changed[field] = at
+	}
+
+	return changed, rows.Err()
+}
+
+// ChangePosition returns the position of the entry with the given id in the
+// stream of changes. An id that doesn't exist is placed after the latest
+// transaction of the entries before it.
+func (r *AuditPostgres) ChangePosition(ctx context.Context, id int64) (models.ChangePosition, error) {
+	pos := models.ChangePosition{ID: id}
+	err := r.db.QueryRowContext(ctx, `SELECT coalesce((SELECT xid FROM audit_log WHERE id = $1),
+	(SELECT max(xid) FROM audit_log WHERE id < $1), 0)`, id).Scan(&pos.XID)
+	return pos, err
+}
+
+// LatestPosition returns a position before the entries of every transaction
+// that is still running, so that reading from it starts with the changes that
+// haven't committed yet.
+func (r *AuditPostgres) LatestPosition(ctx context.Context) (models.ChangePosition, error) {
+	var pos models.ChangePosition
+	err := r.db.QueryRowContext(ctx, "SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&pos.XID)
+	return pos, err
+}
+
+func (r *AuditPostgres) page(ctx context.Context, page *models.PageRequest, query string, args ...interface{}) ([]*models.AuditEntry, *models.PageInfo, error) {
+	entries, err := queryAuditEntries(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, info := paginate(entries, page, func(e *models.AuditEntry) *models.Cursor {
+		return &models.Cursor{ID: e.ID}
+	})
+
+	return entries, info, nil
+}
+
+func queryAuditEntries(ctx context.Context, db DB, query string, args ...interface{}) ([]*models.AuditEntry, error) {
+	entries := make([]*models.AuditEntry, 0)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// scanAuditEntry reads an entry from the columns every audit query selects
+// first, and the columns after them into extra.
+func scanAuditEntry(rows *sql.Rows, extra ...interface{}) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var changes []byte
+	dest := append([]interface{}{&entry.ID, &entry.ActorID, &entry.Action, &entry.Entity, &entry.EntityID, &entry.ListID,
+		&changes, &entry.CreatedAt}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
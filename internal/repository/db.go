@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DB is what the repositories need of the database: a connection pool, or a
+// transaction that several repository calls share.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Tx is a transaction started with DB.Begin. A transaction of the pool is
+// rolled back when its context is done.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Commit() error
+	Rollback() error
+}
+
+type poolDB struct {
+	*sql.DB
+}
+
+func (db poolDB) Begin(ctx context.Context) (Tx, error) {
+	return db.DB.BeginTx(ctx, nil)
+}
+
+// sharedTx runs every statement in one transaction. Transactions begun on it
+// are savepoints, so a repository method can still roll back its own
+// statements without ending the shared transaction.
+type sharedTx struct {
+	*sql.Tx
+	savepoints int
+}
+
+func (t *sharedTx) Begin(ctx context.Context) (Tx, error) {
+	t.savepoints++
+	sp := &savepoint{Tx: t.Tx, name: fmt.Sprintf("sp%d", t.savepoints)}
+
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+sp.name); err != nil {
+		return nil, err
+	}
+
+	return sp, nil
+}
+
+type savepoint struct {
+	*sql.Tx
+	name string
+	done bool
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+
+	_, err := sp.Exec("RELEASE SAVEPOINT " + sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+
+	if _, err := sp.Exec("ROLLBACK TO SAVEPOINT " + sp.name); err != nil {
+		return err
+	}
+
+	_, err := sp.Exec("RELEASE SAVEPOINT " + sp.name)
+	return err
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// itemSorts maps the sort fields of an item filter to their keysets. Items
+// without a due date sort after all others.
+var itemSorts = map[string]keyset{
+	"":         {id: "ti.id"},
+	"due":      {key: "COALESCE(ti.due, 'infinity')", cast: "::timestamptz", id: "ti.id"},
+	"priority": {key: "ti.priority", cast: "::smallint", id: "ti.id"},
+	"title":    {key: "ti.title", id: "ti.id"},
+}
+
+func itemSortKeyset(f *models.ItemFilter) keyset {
+	k := itemSorts[f.Sort]
+	k.desc = f.Desc
+	return k
+}
+
+// itemSortCursor returns the cursor of an item in the order of f.
+func itemSortCursor(f *models.ItemFilter) func(*models.ToDoItem) *models.Cursor {
+	return func(i *models.ToDoItem) *models.Cursor {
+		c := &models.Cursor{ID: int64(i.ID)}
+
+		switch f.Sort {
+		case "due":
+			c.Key = "infinity"
+			if i.Due != nil {
+				c.Key = i.Due.Format(time.RFC3339Nano)
+			}
+		case "priority":
+			c.Key = strconv.Itoa(i.Priority)
+		case "title":
+			c.Key = i.Title
+		}
+
+		return c
+	}
+}
+
+// filterCondition compiles the terms of f into a condition on todo_items ti
+// and lists_items li. Every value is passed as a parameter, starting at
+// $argId.
+func filterCondition(f *models.ItemFilter, argId int) (string, []interface{}) {
+	conditions := make([]string, 0, len(f.Terms))
+	args := make([]interface{}, 0)
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", argId+len(args)-1)
+	}
+
+	for _, t := range f.Terms {
+		cond := termCondition(t, arg)
+		if t.Negate {
+			cond = fmt.Sprintf("(%s) IS NOT TRUE", cond)
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", nil
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func termCondition(t *models.FilterTerm, arg func(interface{}) string) string {
+	op := t.Op
+	if op == ":" {
+		op = "="
+	}
+
+	switch t.Field {
+	case models.FilterText:
+		p := arg(likePattern(t.Value.(string)))
+		return fmt.Sprintf("(ti.title ILIKE %s OR ti.description ILIKE %s)", p, p)
+
+	case models.FilterTitle:
+		return fmt.Sprintf("ti.title ILIKE %s", arg(likePattern(t.Value.(string))))
+
+	case models.FilterDone:
+		return fmt.Sprintf("ti.done = %s", arg(t.Value))
+
+	case models.FilterDue:
+		if t.Value == nil {
+			return "ti.due IS NULL"
+		}
+		if !t.Day {
+			return fmt.Sprintf("ti.due %s %s", op, arg(t.Value))
+		}
+
+		day := t.Value.(time.Time)
+		next := day.AddDate(0, 0, 1)
+		switch t.Op {
+		case "<":
+			return fmt.Sprintf("ti.due < %s", arg(day))
+		case "<=":
+			return fmt.Sprintf("ti.due < %s", arg(next))
+		case ">":
+			return fmt.Sprintf("ti.due >= %s", arg(next))
+		case ">=":
+			return fmt.Sprintf("ti.due >= %s", arg(day))
+		}
+		return fmt.Sprintf("(ti.due >= %s AND ti.due < %s)", arg(day), arg(next))
+
+	case models.FilterLabel:
+		if t.Value == nil {
+			return "cardinality(ti.labels) = 0"
+		}
+		return fmt.Sprintf("%s = ANY(ti.labels)", arg(t.Value))
+
+	case models.FilterPriority:
+		return fmt.Sprintf("ti.priority %s %s", op, arg(t.Value))
+
+	case models.FilterAssignee:
+		if t.Value == nil {
+			return "ti.assignee_id IS NULL"
+		}
+		return fmt.Sprintf("ti.assignee_id = %s", arg(t.Value))
+
+	case models.FilterList:
+		return fmt.Sprintf("li.list_id = %s", arg(t.Value))
+	}
+
+	return "FALSE"
+}
+
+// likePattern matches s anywhere, with LIKE wildcards in s taken literally.
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
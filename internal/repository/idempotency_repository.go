@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type IdempotencyPostgres struct {
+	db DB
+}
+
+func NewIdempotencyPostgres(db DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+// Claim reserves a key for a request with the given fingerprint. It returns
+// nil when the caller holds the key and must run the request, or the record
+// of the earlier request that holds it. Keys created before expired, and keys
+// whose request never completed and that were claimed before stale, are taken
+// over.
+func (r *IdempotencyPostgres) Claim(ctx context.Context, userId int, key, fingerprint string, expired, stale time.Time) (*models.IdempotencyRecord, error) {
+	// a record can be purged between the two statements, so claiming is
+	// retried once
+	for attempt := 0; attempt < 2; attempt++ {
+		var claimed bool
+		err := r.db.QueryRowContext(ctx, `INSERT INTO idempotency_keys (user_id, key, fingerprint) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = NULL, headers = NULL, body = NULL, created_at = now(), completed_at = NULL
+		WHERE idempotency_keys.created_at < $4 OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.created_at < $5)
+		RETURNING true`, userId, key, fingerprint, expired, stale).Scan(&claimed)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		record, err := r.get(ctx, userId, key)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return record, err
+		}
+	}
+
+	return nil, ErrConflict
+}
+
+func (r *IdempotencyPostgres) get(ctx context.Context, userId int, key string) (*models.IdempotencyRecord, error) {
+	record := &models.IdempotencyRecord{}
+	var status sql.NullInt64
+	var headers []byte
+
+	err := r.db.QueryRowContext(ctx, "SELECT fingerprint, status, headers, body, completed_at FROM idempotency_keys WHERE user_id = $1 AND key = $2",
+		userId, key).Scan(&record.Fingerprint, &status, &headers, &record.Body, &record.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Status = int(status.Int64)
+	if headers != nil {
+		if err := json.Unmarshal(headers, &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
+
+// Complete stores the response of the request that holds a key.
+func (r *IdempotencyPostgres) Complete(ctx context.Context, userId int, key string, record *models.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	return requireRows(r.db.ExecContext(ctx, `UPDATE idempotency_keys SET status = $3, headers = $4, body = $5, completed_at = now()
+	WHERE user_id = $1 AND key = $2 AND fingerprint = $6 AND completed_at IS NULL`,
+		userId, key, record.Status, headers, record.Body, record.Fingerprint))
+}
+
+// Release frees a key whose request didn't complete, so that it can be
+// retried.
+func (r *IdempotencyPostgres) Release(ctx context.Context, userId int, key, fingerprint string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND fingerprint = $3 AND completed_at IS NULL",
+		userId, key, fingerprint)
+	return err
+}
+
+// Purge deletes the keys created before the given time and returns their
+// number.
+func (r *IdempotencyPostgres) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type ListTemplatePostgres struct {
+	db DB
+}
+
+func NewListTemplatePostgres(db DB) *ListTemplatePostgres {
+	return &ListTemplatePostgres{db: db}
+}
+
+func (r *ListTemplatePostgres) CreateFromList(ctx context.Context, userId, listId int, input *models.TemplateInput) (*models.ListTemplate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t := &models.ListTemplate{UserID: userId, Shared: input.Shared}
+	query := "SELECT tl.title, tl.description FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL"
+	if err := tx.QueryRowContext(ctx, query, userId, listId).Scan(&t.Title, &t.Description); err != nil {
+		return nil, err
+	}
+
+	if input.Title != "" {
+		t.Title = input.Title
+	}
+
+	items, err := listItems(ctx, tx, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := input.Anchor
+	if anchor == nil {
+		for _, item := range items {
+			if item.Due != nil && (anchor == nil || item.Due.Before(*anchor)) {
+				anchor = item.Due
+			}
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO list_templates (user_id, title, description, shared) VALUES ($1, $2, $3, $4) RETURNING id",
+		userId, t.Title, t.Description, t.Shared).Scan(&t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		ti := &models.TemplateItem{
+			Title:       item.Title,
+			Description: item.Description,
+			Labels:      item.Labels,
+			Position:    item.Position,
+		}
+
+		if item.Due != nil {
+			offset := int64(item.Due.Sub(*anchor) / time.Second)
+			ti.DueOffset = &offset
+		}
+
+		_, err := tx.ExecContext(ctx, `INSERT INTO template_items (template_id, title, description, due_offset, labels, position)
+		VALUES ($1, $2, $3, $4, $5, $6)`, t.ID, ti.Title, ti.Description, ti.DueOffset, labelsArray(ti.Labels), ti.Position)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Items = append(t.Items, ti)
+	}
+
+	return t, tx.Commit()
+}
+
+var templateKeyset = keyset{key: "title", id: "id"}
+
+func (r *ListTemplatePostgres) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ListTemplate, *models.PageInfo, error) {
+	templates := make([]*models.ListTemplate, 0)
+
+	cond, order, args, err := templateKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf("SELECT id, user_id, title, description, shared FROM list_templates WHERE %s AND %s %s", visibleTo(1), cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.ListTemplate
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Title, &t.Description, &t.Shared); err != nil {
+			return nil, nil, err
+		}
+		templates = append(templates, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	templates, info := paginate(templates, page, func(t *models.ListTemplate) *models.Cursor {
+		return &models.Cursor{Key: t.Title, ID: int64(t.ID)}
+	})
+
+	return templates, info, nil
+}
+
+func (r *ListTemplatePostgres) GetById(ctx context.Context, userId, templateId int) (*models.ListTemplate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := getTemplate(ctx, tx, userId, templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, tx.Commit()
+}
+
+func (r *ListTemplatePostgres) Delete(ctx context.Context, userId, templateId int) error {
+	return requireRows(r.db.ExecContext(ctx, "DELETE FROM list_templates WHERE id = $1 AND user_id = $2", templateId, userId))
+}
+
+func (r *ListTemplatePostgres) Instantiate(ctx context.Context, userId, templateId int, input *models.InstantiateInput) (*models.ToDoList, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := getTemplate(ctx, tx, userId, templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.ToDoList{Title: t.Title, Description: t.Description}
+	if input.Title != "" {
+		list.Title = input.Title
+	}
+
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := insertList(ctx, tx, userId, list); err != nil {
+		return nil, err
+	}
+
+	for _, ti := range t.Items {
+		item := &models.ToDoItem{
+			Title:       ti.Title,
+			Description: ti.Description,
+			Labels:      ti.Labels,
+			Position:    ti.Position,
+		}
+
+		if ti.DueOffset != nil {
+			due := input.Anchor.Add(time.Duration(*ti.DueOffset) * time.Second)
+			item.Due = &due
+		}
+
+		if _, err := insertItem(ctx, tx, list.ID, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, tx.Commit()
+}
+
+// getTemplate loads a template visible to the user, with items.
+func getTemplate(ctx context.Context, tx Tx, userId, templateId int) (*models.ListTemplate, error) {
+	t := &models.ListTemplate{}
+	query := "SELECT id, user_id, title, description, shared FROM list_templates WHERE id = $1 AND " + visibleTo(2)
+	if err := tx.QueryRowContext(ctx, query, templateId, userId).Scan(&t.ID, &t.UserID, &t.Title, &t.Description, &t.Shared); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT title, description, due_offset, labels, position FROM template_items WHERE template_id = $1 ORDER BY position", templateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ti models.TemplateItem
+		if err := rows.Scan(&ti.Title, &ti.Description, &ti.DueOffset, pq.Array(&ti.Labels), &ti.Position); err != nil {
+			return nil, err
+		}
+		t.Items = append(t.Items, &ti)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// changeChannel is the channel that new entries of the audit log are
+// announced on, with the id of their list as payload.
+const changeChannel = "audit_log"
+
+// ChangeListener listens for the changes committed by any instance of the
+// server and wakes the subscribers of the changed lists. The notifications
+// only tell that there is something new; the subscribers read it from the
+// audit log themselves.
+type ChangeListener struct {
+	listener *pq.Listener
+
+	mu          sync.Mutex
+	subscribers map[chan<- struct{}]int
+}
+
+// NewChangeListener opens a connection of its own to the database, which it
+// keeps reopening when it is lost.
+func NewChangeListener(dbURL string) (*ChangeListener, error) {
+	l := &ChangeListener{subscribers: make(map[chan<- struct{}]int)}
+
+	l.listener = pq.NewListener(dbURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("listening for changes: %v", err)
+		}
+	})
+
+	if err := l.listener.Listen(changeChannel); err != nil {
+		l.listener.Close()
+		return nil, err
+	}
+
+	go l.run()
+	return l, nil
+}
+
+// Subscribe sends to wake, without blocking, whenever a change of the list
+// is committed, or of any list if listId is 0, until ctx is done or
+// unsubscribe is called. wake should be buffered.
+func (l *ChangeListener) Subscribe(ctx context.Context, listId int, wake chan<- struct{}) (unsubscribe func()) {
+	l.mu.Lock()
+	l.subscribers[wake] = listId
+	l.mu.Unlock()
+
+	remove := func() {
+		l.mu.Lock()
+		delete(l.subscribers, wake)
+		l.mu.Unlock()
+	}
+	stop := context.AfterFunc(ctx, remove)
+
+	return func() {
+		stop()
+		remove()
+	}
+}
+
+func (l *ChangeListener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *ChangeListener) run() {
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	for {
+		select {
+		case n, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+
+			// after a reconnect, notifications may have been missed, which
+			// is announced with a nil one
+			listId := 0
+			if n != nil {
+				listId, _ = strconv.Atoi(n.Extra)
+			}
+			l.wake(listId)
+		case <-ping.C:
+			// finds a broken connection that no notification would
+			go l.listener.Ping()
+		}
+	}
+}
+
+// wake wakes the subscribers of the list, or all of them if listId is 0.
+func (l *ChangeListener) wake(listId int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for wake, subscribed := range l.subscribers {
+		if listId == 0 || subscribed == 0 || subscribed == listId {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
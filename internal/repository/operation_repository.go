@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type OperationPostgres struct {
+	db DB
+}
+
+func NewOperationPostgres(db DB) *OperationPostgres {
+	return &OperationPostgres{db: db}
+}
+
+// Push adds a step to the user's undo stack. A new step discards everything
+// that was undone before it, so it can no longer be redone.
+func (r *OperationPostgres) Push(ctx context.Context, userId int, auditIds []int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_operations WHERE user_id = $1 AND undone_at IS NOT NULL", userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO user_operations (user_id, audit_ids) VALUES ($1, $2)", userId, pq.Array(auditIds)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ClaimUndo marks the user's latest step made after since as undone and
+// returns it. It returns sql.ErrNoRows when there is nothing to undo.
+func (r *OperationPostgres) ClaimUndo(ctx context.Context, userId int, since time.Time) (*models.Operation, error) {
+	return r.claim(ctx, `UPDATE user_operations SET undone_at = now() WHERE id = (
+		SELECT id FROM user_operations WHERE user_id = $1 AND undone_at IS NULL AND created_at >= $2
+		ORDER BY id DESC LIMIT 1 FOR UPDATE) RETURNING id, audit_ids, created_at`, userId, since)
+}
+
+// ClaimRedo marks the user's most recently undone step as done again and
+// returns it. It returns sql.ErrNoRows when there is nothing to redo.
+func (r *OperationPostgres) ClaimRedo(ctx context.Context, userId int, since time.Time) (*models.Operation, error) {
+	return r.claim(ctx, `UPDATE user_operations SET undone_at = NULL WHERE id = (
+		SELECT id FROM user_operations WHERE user_id = $1 AND undone_at IS NOT NULL AND created_at >= $2
+		ORDER BY undone_at DESC, id ASC LIMIT 1 FOR UPDATE) RETURNING id, audit_ids, created_at`, userId, since)
+}
+
+// Discard removes a step from the user's stack, so it can be neither undone
+// nor redone.
+func (r *OperationPostgres) Discard(ctx context.Context, userId int, opId int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM user_operations WHERE id = $1 AND user_id = $2", opId, userId)
+	return err
+}
+
+func (r *OperationPostgres) claim(ctx context.Context, query string, userId int, since time.Time) (*models.Operation, error) {
+	op := &models.Operation{}
+	var auditIds []int64
+
+	err := r.db.QueryRowContext(ctx, query, userId, since).Scan(&op.ID, pq.Array(&auditIds), &op.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	op.Entries, err = queryAuditEntries(ctx, r.db, `SELECT id, actor_id, action, entity, entity_id, list_id, changes, created_at
+	FROM audit_log WHERE id = ANY($1) ORDER BY id`, pq.Array(auditIds))
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
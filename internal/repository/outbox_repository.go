@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type OutboxPostgres struct {
+	db DB
+}
+
+func NewOutboxPostgres(db DB) *OutboxPostgres {
+	return &OutboxPostgres{db: db}
+}
+
+// Append adds events to the outbox, in the transaction of the repository.
+func (r *OutboxPostgres) Append(ctx context.Context, events ...*models.DomainEvent) error {
+	for _, e := range events {
+		changes, err := json.Marshal(e.Changes)
+		if err != nil {
+			return err
+		}
+
+		if err := r.db.QueryRowContext(ctx, `INSERT INTO outbox (type, actor_id, list_id, entity_id, audit_id, changes)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+			e.Type, e.ActorID, e.ListID, e.EntityID, e.AuditID, changes,
+		).Scan(&e.ID, &e.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Claim takes up to limit due events, oldest first, and counts an attempt for
+// each. A claimed event isn't due again until the lease ends, so that it is
+// relayed again if its relay stops before publishing it. Concurrent claims
+// skip each other's events.
+func (r *OutboxPostgres) Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.DomainEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `UPDATE outbox SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+	WHERE id IN (
+		SELECT id FROM outbox WHERE published_at IS NULL AND next_attempt_at <= now()
+		ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, type, actor_id, list_id, entity_id, audit_id, changes, attempts, created_at`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.DomainEvent, 0)
+	for rows.Next() {
+		e := &models.DomainEvent{}
+		var changes []byte
+
+		if err := rows.Scan(&e.ID, &e.Type, &e.ActorID, &e.ListID, &e.EntityID, &e.AuditID, &changes, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// UPDATE ... RETURNING doesn't keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// Publish marks an event as delivered to every subscriber.
+func (r *OutboxPostgres) Publish(ctx context.Context, eventId int64) error {
+	return requireRows(r.db.ExecContext(ctx, "UPDATE outbox SET published_at = now() WHERE id = $1", eventId))
+}
+
+// Retry makes an event that some subscriber failed due again at the given time.
+func (r *OutboxPostgres) Retry(ctx context.Context, eventId int64, at time.Time) error {
+	return requireRows(r.db.ExecContext(ctx, "UPDATE outbox SET next_attempt_at = $2 WHERE id = $1 AND published_at IS NULL", eventId, at))
+}
+
+// Purge removes the events published before the given time.
+func (r *OutboxPostgres) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM outbox WHERE published_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// keyset describes the order of a collection: by a sort key expression and
+// then by an id expression, which makes the order total and stable.
+type keyset struct {
+	key  string
+	cast string
+	id   string
+	desc bool
+}
+
+// clause returns the condition selecting the requested page and the matching
+// ORDER BY and LIMIT clause. Placeholders start at $argId. One row more than
+// the page size is requested to find out whether another page follows. A
+// cursor whose key doesn't fit the order, such as one of another collection,
+// is models.ErrInvalidCursor.
+func (k keyset) clause(page *models.PageRequest, argId int) (string, string, []interface{}, error) {
+	if page == nil {
+		page = &models.PageRequest{}
+	}
+
+	cursor, backward := page.After, false
+	if page.Before != nil {
+		cursor, backward = page.Before, true
+	}
+
+	desc := k.desc != backward
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	order := fmt.Sprintf("ORDER BY %s %s LIMIT %d", k.id, dir, page.Size()+1)
+	if k.key != "" {
+		order = fmt.Sprintf("ORDER BY %s %s, %s %s LIMIT %d", k.key, dir, k.id, dir, page.Size()+1)
+	}
+
+	if cursor == nil {
+		return "TRUE", order, nil, nil
+	}
+
+	if k.key == "" {
+		return fmt.Sprintf("%s %s $%d", k.id, cmp, argId), order, []interface{}{cursor.ID}, nil
+	}
+
+	if !k.fits(cursor.Key) {
+		return "", "", nil, models.ErrInvalidCursor
+	}
+
+	cond := fmt.Sprintf("(%s, %s) %s ($%d%s, $%d)", k.key, k.id, cmp, argId, k.cast, argId+1)
+	return cond, order, []interface{}{cursor.Key, cursor.ID}, nil
+}
+
+// fits reports whether the sort key of a cursor can be cast as the keyset's
+// key is, so that a foreign cursor doesn't fail the query.
+func (k keyset) fits(key string) bool {
+	var err error
+	switch k.cast {
+	case "::timestamptz":
+		if key == "infinity" {
+			return true
+		}
+		_, err = time.Parse(time.RFC3339Nano, key)
+	case "::real":
+		_, err = strconv.ParseFloat(key, 32)
+	case "::smallint":
+		_, err = strconv.ParseInt(key, 10, 16)
+	}
+	return err == nil
+}
+
+// paginate trims the extra row fetched by clause, restores the collection
+// order of backward pages and works out the neighbouring cursors.
+func paginate[T any](rows []T, page *models.PageRequest, cursorOf func(T) *models.Cursor) ([]T, *models.PageInfo) {
+	if page == nil {
+		page = &models.PageRequest{}
+	}
+
+	info := &models.PageInfo{}
+	more := len(rows) > page.Size()
+	if more {
+		rows = rows[:page.Size()]
+	}
+
+	if page.Before != nil {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, info
+	}
+
+	first, last := cursorOf(rows[0]), cursorOf(rows[len(rows)-1])
+	if page.Before != nil {
+		info.Next = last
+		if more {
+			info.Prev = first
+		}
+	} else {
+		if more {
+			info.Next = last
+		}
+		if page.After != nil {
+			info.Prev = first
+		}
+	}
+
+	return rows, info
+}
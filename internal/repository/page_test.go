@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"errors"
+	"testing"
+)
+
+func TestKeysetClauseCursorKey(t *testing.T) {
+	tests := []struct {
+		name string
+		k    keyset
+		key  string
+		ok   bool
+	}{
+		{"deletion time", trashedListKeyset, "2026-10-19T08:30:00.123456Z", true},
+		{"position as deletion time", trashedItemKeyset, "a0V", false},
+		{"rank", searchKeyset, "0.0607927", true},
+		{"deletion time as rank", searchKeyset, "2026-10-19T08:30:00Z", false},
+		{"no due date", itemSorts["due"], "infinity", true},
+		{"priority as due date", itemSorts["due"], "3", false},
+		{"priority", itemSorts["priority"], "3", true},
+		{"priority out of range", itemSorts["priority"], "40000", false},
+		{"title as priority", itemSorts["priority"], "groceries", false},
+		{"any title", itemSorts["title"], "2026-10-19T08:30:00Z", true},
+		{"any position", itemKeyset, "0.5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &models.PageRequest{After: &models.Cursor{Key: tt.key, ID: 7}}
+			_, _, _, err := tt.k.clause(page, 1)
+			if ok := err == nil; ok != tt.ok {
+				t.Errorf("clause(%q) = %v, want ok = %v", tt.key, err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, models.ErrInvalidCursor) {
+				t.Errorf("clause(%q) = %v, want %v", tt.key, err, models.ErrInvalidCursor)
+			}
+		})
+	}
+}
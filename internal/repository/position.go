@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrPositionSelf is returned when a row is to be placed next to itself.
+var ErrPositionSelf = errors.New("cannot position relative to itself")
+
+// lastPosition returns a rank key that sorts after every row of the scope.
+func lastPosition(ctx context.Context, tx Tx, table, scopeColumn string, scopeId int) (string, error) {
+	var last string
+	query := fmt.Sprintf("SELECT COALESCE(MAX(position), '') FROM %s WHERE %s = $1", table, scopeColumn)
+	if err := tx.QueryRowContext(ctx, query, scopeId).Scan(&last); err != nil {
+		return "", err
+	}
+
+	return rankBetween(last, "")
+}
+
+// nearPosition returns a rank key placing rowId right before or right after
+// the sibling named in input. Callers must hold a lock on the scope so the
+// neighbours can't change underneath them.
+func nearPosition(ctx context.Context, tx Tx, table, scopeColumn, idColumn string, scopeId, rowId int, input *models.PositionInput) (string, error) {
+	if input.Sibling() == rowId {
+		return "", ErrPositionSelf
+	}
+
+	var sibling string
+	query := fmt.Sprintf("SELECT position FROM %s WHERE %s = $1 AND %s = $2", table, scopeColumn, idColumn)
+	if err := tx.QueryRowContext(ctx, query, scopeId, input.Sibling()).Scan(&sibling); err != nil {
+		return "", err
+	}
+
+	cmp, order := "<", "DESC"
+	if input.After != nil {
+		cmp, order = ">", "ASC"
+	}
+
+	var neighbour string
+	query = fmt.Sprintf("SELECT position FROM %s WHERE %s = $1 AND %s <> $2 AND position %s $3 ORDER BY position %s LIMIT 1",
+		table, scopeColumn, idColumn, cmp, order)
+	err := tx.QueryRowContext(ctx, query, scopeId, rowId, sibling).Scan(&neighbour)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if input.After != nil {
+		return rankBetween(sibling, neighbour)
+	}
+
+	return rankBetween(neighbour, sibling)
+}
+
+// setPosition stores a new rank key and reports sql.ErrNoRows when the row
+// doesn't exist in the scope.
+func setPosition(ctx context.Context, tx Tx, table, scopeColumn, idColumn string, scopeId, rowId int, position string) error {
+	query := fmt.Sprintf("UPDATE %s SET position = $1 WHERE %s = $2 AND %s = $3", table, scopeColumn, idColumn)
+	return requireRows(tx.ExecContext(ctx, query, position, scopeId, rowId))
+}
+
+// lockList serializes positioning of items inside a list across all members.
+func lockList(ctx context.Context, tx Tx, listId int) error {
+	var id int
+	return tx.QueryRowContext(ctx, "SELECT id FROM todo_lists WHERE id = $1 FOR UPDATE", listId).Scan(&id)
+}
+
+// lockUser serializes positioning of a user's lists.
+func lockUser(ctx context.Context, tx Tx, userId int) error {
+	var id int
+	return tx.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userId).Scan(&id)
+}
+
+// positionTaken reports whether another row of the scope already uses position.
+func positionTaken(ctx context.Context, tx Tx, table, scopeColumn, idColumn string, scopeId, rowId int, position string) (bool, error) {
+	var taken bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s = $1 AND position = $2 AND %s <> $3)", table, scopeColumn, idColumn)
+	err := tx.QueryRowContext(ctx, query, scopeId, position, rowId).Scan(&taken)
+
+	return taken, err
+}
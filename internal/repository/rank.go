@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ranks are fractional index keys: an integer part whose first character
+// encodes its length ('a' is two characters long, 'b' three, ... and 'Z', 'Y',
+// ... for the negative range) followed by an optional fraction. rankDigits is
+// ordered by byte value, so ranks compare the same way in Go and in Postgres
+// columns declared with COLLATE "C".
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const rankZero = "a0"
+
+var errInvalidRank = errors.New("invalid rank")
+
+// rankBetween returns a key that sorts strictly between lo and hi. An empty lo
+// means "before everything", an empty hi means "after everything". Appending
+// to the end only ever increments the integer part, so keys stay short.
+func rankBetween(lo, hi string) (string, error) {
+	if lo != "" && hi != "" && lo >= hi {
+		return "", errInvalidRank
+	}
+
+	switch {
+	case lo == "" && hi == "":
+		return rankZero, nil
+
+	case lo == "":
+		ib, err := rankInteger(hi)
+		if err != nil {
+			return "", err
+		}
+		if ib < hi {
+			return ib, nil
+		}
+		if dec := rankDecrement(ib); dec != "" {
+			return dec, nil
+		}
+		return ib + rankMidpoint("", hi[len(ib):]), nil
+
+	case hi == "":
+		ia, err := rankInteger(lo)
+		if err != nil {
+			return "", err
+		}
+		if inc := rankIncrement(ia); inc != "" {
+			return inc, nil
+		}
+		return ia + rankMidpoint(lo[len(ia):], ""), nil
+	}
+
+	ia, err := rankInteger(lo)
+	if err != nil {
+		return "", err
+	}
+	ib, err := rankInteger(hi)
+	if err != nil {
+		return "", err
+	}
+
+	if ia == ib {
+		return ia + rankMidpoint(lo[len(ia):], hi[len(ib):]), nil
+	}
+
+	if inc := rankIncrement(ia); inc != "" && inc < hi {
+		return inc, nil
+	}
+
+	return ia + rankMidpoint(lo[len(ia):], ""), nil
+}
+
+// rankInteger returns the integer part of key.
+func rankInteger(key string) (string, error) {
+	if key == "" {
+		return "", errInvalidRank
+	}
+
+	n := rankIntegerLength(key[0])
+	if n == 0 || n > len(key) {
+		return "", errInvalidRank
+	}
+
+	if strings.HasSuffix(key[n:], rankDigits[:1]) {
+		return "", errInvalidRank
+	}
+
+	return key[:n], nil
+}
+
+func rankIntegerLength(head byte) int {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2
+	}
+
+	return 0
+}
+
+// rankIncrement returns the next integer after x, or "" when x is the largest
+// integer that can be encoded.
+func rankIncrement(x string) string {
+	head, digits := x[0], []byte(x[1:])
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(rankDigits, digits[i]) + 1
+		if d < len(rankDigits) {
+			digits[i] = rankDigits[d]
+			return string(head) + string(digits)
+		}
+		digits[i] = rankDigits[0]
+	}
+
+	switch head {
+	case 'Z':
+		return rankZero
+	case 'z':
+		return ""
+	}
+
+	head++
+	if head > 'a' {
+		digits = append(digits, rankDigits[0])
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+
+	return string(head) + string(digits)
+}
+
+// rankDecrement returns the integer before x, or "" when x is the smallest
+// integer that can be encoded.
+func rankDecrement(x string) string {
+	head, digits := x[0], []byte(x[1:])
+	last := rankDigits[len(rankDigits)-1]
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(rankDigits, digits[i]) - 1
+		if d >= 0 {
+			digits[i] = rankDigits[d]
+			return string(head) + string(digits)
+		}
+		digits[i] = last
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(last)
+	case 'A':
+		return ""
+	}
+
+	head--
+	if head < 'Z' {
+		digits = append(digits, last)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+
+	return string(head) + string(digits)
+}
+
+// rankMidpoint returns a fraction between lo and hi, where hi == "" means no
+// upper bound.
+func rankMidpoint(lo, hi string) string {
+	if hi != "" {
+		n := 0
+		for n < len(hi) && rankDigitAt(lo, n) == hi[n] {
+			n++
+		}
+
+		if n > 0 {
+			if n > len(lo) {
+				return hi[:n] + rankMidpoint("", hi[n:])
+			}
+			return hi[:n] + rankMidpoint(lo[n:], hi[n:])
+		}
+	}
+
+	digitLo := 0
+	if lo != "" {
+		digitLo = strings.IndexByte(rankDigits, lo[0])
+	}
+
+	digitHi := len(rankDigits)
+	if hi != "" {
+		digitHi = strings.IndexByte(rankDigits, hi[0])
+	}
+
+	if digitHi-digitLo > 1 {
+		return string(rankDigits[(digitLo+digitHi+1)/2])
+	}
+
+	if len(hi) > 1 {
+		return hi[:1]
+	}
+
+	rest := ""
+	if lo != "" {
+		rest = lo[1:]
+	}
+
+	return string(rankDigits[digitLo]) + rankMidpoint(rest, "")
+}
+
+func rankDigitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+
+	return rankDigits[0]
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi string
+		want   string
+	}{
+		{"first key", "", "", "a0"},
+		{"last", "a0", "", "a1"},
+		{"last crosses into a longer integer", "az", "", "b00"},
+		{"last after a longer integer", "b00", "", "b01"},
+		{"first", "", "a0", "Zz"},
+		{"first below the smallest integer", "", "A00000000000000000000000000", "A00000000000000000000000000V"},
+		{"between neighbouring integers", "a0", "a1", "a0V"},
+		{"between the negative and positive range", "Zz", "a0", "ZzV"},
+		{"between an integer and a fraction", "a0", "a0V", "a0G"},
+		{"between a fraction and an integer", "a0V", "a1", "a0l"},
+		{"between fractions", "a01", "a02", "a01V"},
+		{"below a fraction of the same integer", "a0", "a01", "a00V"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rankBetween(tt.lo, tt.hi)
+			if err != nil {
+				t.Fatalf("rankBetween(%q, %q): %v", tt.lo, tt.hi, err)
+			}
+			if got != tt.want {
+				t.Errorf("rankBetween(%q, %q) = %q, want %q", tt.lo, tt.hi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi string
+	}{
+		{"equal", "a0", "a0"},
+		{"reversed", "a1", "a0"},
+		{"integer too short", "a", ""},
+		{"unknown head", "!0", ""},
+		{"trailing zero", "a00", ""},
+		{"trailing zero above", "a0", "b000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := rankBetween(tt.lo, tt.hi); err != errInvalidRank {
+				t.Errorf("rankBetween(%q, %q) = %q, %v, want errInvalidRank", tt.lo, tt.hi, got, err)
+			}
+		})
+	}
+}
+
+func TestRankIncrementDecrement(t *testing.T) {
+	tests := []struct {
+		x, inc, dec string
+	}{
+		{"a0", "a1", "Zz"},
+		{"az", "b00", "ay"},
+		{"Zz", "a0", "Zy"},
+		{"b0z", "b10", "b0y"},
+		{"bzz", "c000", "bzy"},
+		{"Y00", "Y01", "Xzzz"},
+		{"z" + strings.Repeat("z", 26), "", "z" + strings.Repeat("z", 25) + "y"},
+		{"A" + strings.Repeat("0", 26), "A" + strings.Repeat("0", 25) + "1", ""},
+	}
+
+	for _, tt := range tests {
+		if got := rankIncrement(tt.x); got != tt.inc {
+			t.Errorf("rankIncrement(%q) = %q, want %q", tt.x, got, tt.inc)
+		}
+		if got := rankDecrement(tt.x); got != tt.dec {
+			t.Errorf("rankDecrement(%q) = %q, want %q", tt.x, got, tt.dec)
+		}
+	}
+}
+
+// TestRankSequences appends and prepends many keys, as adding items to the
+// end or the start of a list does, and keeps inserting into the same gap.
+func TestRankSequences(t *testing.T) {
+	keys := []string{rankZero}
+	for i := 0; i < 5000; i++ {
+		last, err := rankBetween(keys[len(keys)-1], "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, last)
+
+		first, err := rankBetween("", keys[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append([]string{first}, keys...)
+	}
+	checkRanks(t, keys)
+
+	if n := len(keys[len(keys)-1]); n > 4 {
+		t.Errorf("appending 5000 keys made them %d characters long", n)
+	}
+
+	lo, hi := "a0", "a1"
+	for i := 0; i < 500; i++ {
+		mid, err := rankBetween(lo, hi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkRanks(t, []string{lo, mid, hi})
+		if i%2 == 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+}
+
+func checkRanks(t *testing.T, keys []string) {
+	t.Helper()
+
+	for i, key := range keys {
+		if _, err := rankInteger(key); err != nil {
+			t.Fatalf("key %q is invalid", key)
+		}
+		if i > 0 && keys[i-1] >= key {
+			t.Fatalf("keys out of order: %q >= %q", keys[i-1], key)
+		}
+	}
+}
+
+func FuzzRankBetween(f *testing.F) {
+	f.Add("", "")
+	f.Add("a0", "")
+	f.Add("", "a0")
+	f.Add("a0", "a1")
+	f.Add("Zz", "a0")
+	f.Add("a0V", "a0W")
+	f.Add("az", "b001")
+
+	f.Fuzz(func(t *testing.T, lo, hi string) {
+		if !validRank(lo) || !validRank(hi) || (lo != "" && hi != "" && lo >= hi) {
+			return
+		}
+
+		mid, err := rankBetween(lo, hi)
+		if err != nil {
+			// the extremes of the key space have no room left
+			if rankIncrement(lo) == "" || rankDecrement(hi) == "" {
+				return
+			}
+			t.Fatalf("rankBetween(%q, %q): %v", lo, hi, err)
+		}
+
+		if _, err := rankInteger(mid); err != nil {
+			t.Fatalf("rankBetween(%q, %q) = %q, which is invalid", lo, hi, mid)
+		}
+		if (lo != "" && mid <= lo) || (hi != "" && mid >= hi) {
+			t.Fatalf("rankBetween(%q, %q) = %q, which isn't between them", lo, hi, mid)
+		}
+	})
+}
+
+// validRank reports whether key is "" or a well-formed rank.
+func validRank(key string) bool {
+	if key == "" {
+		return true
+	}
+
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(rankDigits, key[i]) < 0 {
+			return false
+		}
+	}
+
+	_, err := rankInteger(key)
+	return err == nil
+}
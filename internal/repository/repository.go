@@ -2,41 +2,192 @@ package repository
 
 import (
 	"Todo-app/internal/models"
+	"context"
 	"database/sql"
+	"time"
 )
 
 type Authorization interface {
-	Create(u *models.User) (*models.User, error)
-	FindByEmail(email string) (*models.User, error)
-	Find(id int) (*models.User, error)
+	Create(ctx context.Context, u *models.User) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Find(ctx context.Context, id int) (*models.User, error)
+	FindByIds(ctx context.Context, ids []int) ([]*models.User, error)
+	SetTimeZone(ctx context.Context, id int, timeZone string) error
 }
 
 type TodoList interface {
-	Create(userId int, list *models.ToDoList) (int, error)
-	GetAll(userId int) ([]*models.ToDoList, error)
-	GetById(userId, listId int) (*models.ToDoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input *models.UpdateListInput) error
+	Create(ctx context.Context, userId int, list *models.ToDoList) (int, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, listId int) (*models.ToDoList, error)
+	GetByIds(ctx context.Context, userId int, listIds []int) ([]*models.ToDoList, error)
+	Delete(ctx context.Context, userId, listId int, version *int) error
+	Update(ctx context.Context, userId, listId int, input *models.UpdateListInput) error
+	Reorder(ctx context.Context, userId, listId int, input *models.PositionInput) error
+	Duplicate(ctx context.Context, userId, listId int, title string) (*models.ToDoList, error)
+	Restore(ctx context.Context, userId, listId int) error
+	Archive(ctx context.Context, userId, listId int) error
+	Unarchive(ctx context.Context, userId, listId int) error
+	GetArchived(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error)
+	Place(ctx context.Context, userId, listId int, position string) error
 }
 
 type TodoItem interface {
-	Create(listId int, item *models.ToDoItem) (int, error)
-	GetAll(userId, listId int) ([]*models.ToDoItem, error)
-	GetById(userId, itemId int) (*models.ToDoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input *models.UpdateItemInput) error
+	Create(ctx context.Context, listId int, item *models.ToDoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error)
+	GetFirstPages(ctx context.Context, userId int, listIds []int, limit int) (map[int]*models.ItemPage, error)
+	Find(ctx context.Context, userId int, filter *models.ItemFilter, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, itemId int) (*models.ToDoItem, error)
+	GetByIds(ctx context.Context, userId int, itemIds []int) ([]*models.ToDoItem, error)
+	Delete(ctx context.Context, userId, itemId int, version *int) error
+	Update(ctx context.Context, userId, itemId int, input *models.UpdateItemInput) error
+	BulkUpdate(ctx context.Context, userId int, itemIds []int, input *models.UpdateItemInput) error
+	BulkDelete(ctx context.Context, userId int, itemIds []int) error
+	Reorder(ctx context.Context, userId, listId, itemId int, input *models.PositionInput) error
+	Move(ctx context.Context, userId int, input *models.TransferInput) error
+	Copy(ctx context.Context, userId int, input *models.TransferInput) ([]int, error)
+	Restore(ctx context.Context, userId, itemId int) error
+	Place(ctx context.Context, userId, itemId, listId int, position string) error
+}
+
+type ListTemplate interface {
+	CreateFromList(ctx context.Context, userId, listId int, input *models.TemplateInput) (*models.ListTemplate, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ListTemplate, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, templateId int) (*models.ListTemplate, error)
+	Delete(ctx context.Context, userId, templateId int) error
+	Instantiate(ctx context.Context, userId, templateId int, input *models.InstantiateInput) (*models.ToDoList, error)
+}
+
+type Trash interface {
+	GetLists(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedList, *models.PageInfo, error)
+	GetItems(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedItem, *models.PageInfo, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Audit interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	GetByList(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+	GetByItem(ctx context.Context, userId, itemId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+	Find(ctx context.Context, filter *models.AuditFilter, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+	GetChanges(ctx context.Context, userId, listId int, after models.ChangePosition, limit int) (*models.ChangePage, error)
+	LastChanged(ctx context.Context, entity string, entityId int) (map[string]time.Time, error)
+	ChangePosition(ctx context.Context, id int64) (models.ChangePosition, error)
+	LatestPosition(ctx context.Context) (models.ChangePosition, error)
+}
+
+type Operation interface {
+	Push(ctx context.Context, userId int, auditIds []int64) error
+	ClaimUndo(ctx context.Context, userId int, since time.Time) (*models.Operation, error)
+	ClaimRedo(ctx context.Context, userId int, since time.Time) (*models.Operation, error)
+	Discard(ctx context.Context, userId int, opId int64) error
+}
+
+type SmartList interface {
+	Create(ctx context.Context, userId int, input *models.SmartListInput) (*models.SmartList, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.SmartList, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, smartListId int) (*models.SmartList, error)
+	Update(ctx context.Context, userId, smartListId int, input *models.SmartListInput) error
+	Delete(ctx context.Context, userId, smartListId int) error
+}
+
+type Agenda interface {
+	Get(ctx context.Context, userId int, query *models.AgendaQuery) ([]*models.AgendaDay, error)
+}
+
+type Search interface {
+	Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.SearchResult, *models.PageInfo, error)
+}
+
+type Idempotency interface {
+	Claim(ctx context.Context, userId int, key, fingerprint string, expired, stale time.Time) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, userId int, key string, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, userId int, key, fingerprint string) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Token interface {
+	Create(ctx context.Context, token *models.APIToken, hash []byte) error
+	GetAll(ctx context.Context, userId int) ([]*models.APIToken, error)
+	FindUser(ctx context.Context, hash []byte) (*models.User, error)
+	Delete(ctx context.Context, userId, tokenId int) error
+}
+
+type Sync interface {
+	Horizon(ctx context.Context) (int64, error)
+	Changes(ctx context.Context, userId int, token *models.SyncToken, limit int) ([]*models.SyncKey, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, hook *models.Webhook) error
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.Webhook, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, hookId int) (*models.Webhook, error)
+	Update(ctx context.Context, userId, hookId int, input *models.UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, hookId int) error
+	GetDeliveries(ctx context.Context, hookId int, status string, page *models.PageRequest) ([]*models.WebhookDelivery, *models.PageInfo, error)
+	Redeliver(ctx context.Context, hookId int, deliveryId int64) (*models.WebhookDelivery, error)
+	Enqueue(ctx context.Context, auditId int64, listId int, event string) (int64, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookJob, error)
+	Succeed(ctx context.Context, deliveryId int64, responseStatus int) error
+	Fail(ctx context.Context, deliveryId int64, responseStatus *int, reason string, retryAt *time.Time, disableAfter int) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Outbox interface {
+	Append(ctx context.Context, events ...*models.DomainEvent) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.DomainEvent, error)
+	Publish(ctx context.Context, eventId int64) error
+	Retry(ctx context.Context, eventId int64, at time.Time) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Notifications wakes the readers of the audit log when entries are added.
+type Notifications interface {
+	Subscribe(ctx context.Context, listId int, wake chan<- struct{}) (unsubscribe func())
 }
 
 type Repository struct {
 	Authorization
 	TodoList
 	TodoItem
+	ListTemplate
+	Trash
+	Audit
+	Operation
+	Search
+	SmartList
+	Agenda
+	Idempotency
+	Token
+	Sync
+	Webhook
+	Outbox
+
+	pool *sql.DB
+	tx   *sharedTx
 }
 
 func NewRepository(db *sql.DB) *Repository {
+	r := newRepository(poolDB{db})
+	r.pool = db
+	return r
+}
+
+func newRepository(db DB) *Repository {
 	return &Repository{
 		Authorization: NewUserRepository(db),
 		TodoList:      NewTodoListPostgres(db),
 		TodoItem:      NewTodoItemPostgres(db),
+		ListTemplate:  NewListTemplatePostgres(db),
+		Trash:         NewTrashPostgres(db),
+		Audit:         NewAuditPostgres(db),
+		Operation:     NewOperationPostgres(db),
+		Search:        NewSearchPostgres(db),
+		SmartList:     NewSmartListPostgres(db),
+		Agenda:        NewAgendaPostgres(db),
+		Idempotency:   NewIdempotencyPostgres(db),
+		Token:         NewTokenPostgres(db),
+		Sync:          NewSyncPostgres(db),
+		Webhook:       NewWebhookPostgres(db),
+		Outbox:        NewOutboxPostgres(db),
 	}
 }
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+type SearchPostgres struct {
+	db DB
+}
+
+func NewSearchPostgres(db DB) *SearchPostgres {
+	return &SearchPostgres{db: db}
+}
+
+// Lists and items share one ranking. Their ids can collide, so the results
+// are ordered by a key that tells them apart: the id shifted left by one with
+// the lowest bit set for items.
+var searchKeyset = keyset{key: "r.rank", cast: "::real", id: "r.uid", desc: true}
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>"
+
+// escapeHTML is the SQL expression for text with the characters that are
+// special in HTML replaced by entities. Headlines are built from escaped text
+// so that their <mark> tags are the only markup in them.
+func escapeHTML(text string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`, text)
+}
+
+// Find runs a full-text search over the titles and descriptions of the lists
+// and items the user can access, best matches first. Archived lists and their
+// items are included, trashed ones are not. An item in several of the
+// user's lists is found once, with the first of them.
+func (r *SearchPostgres) Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.SearchResult, *models.PageInfo, error) {
+	results := make([]*models.SearchResult, 0)
+
+	cond, order, args, err := searchKeyset.clause(page, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlQuery := fmt.Sprintf(`SELECT r.type, r.id, r.list_id,
+	ts_headline('english', %[4]s, q, 'HighlightAll=true, %[1]s'),
+	ts_headline('english', %[5]s, q, 'MaxFragments=2, MaxWords=20, MinWords=5, %[1]s'),
+	r.rank
+	FROM (
+		SELECT 'list' AS type, tl.id, tl.id AS list_id, tl.title, tl.description,
+		ts_rank(tl.search, q) AS rank, tl.id::bigint << 1 AS uid
+		FROM todo_lists tl INNER JOIN users_lists ul on ul.list_id = tl.id, websearch_to_tsquery('english', $2) q
+		WHERE ul.user_id = $1 AND tl.deleted_at IS NULL AND tl.search @@ q
+		UNION ALL
+		(SELECT DISTINCT ON (ti.id) 'item', ti.id, li.list_id, ti.title, ti.description,
+		ts_rank(ti.search, q), ti.id::bigint << 1 | 1
+		FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+		INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id,
+		websearch_to_tsquery('english', $2) q
+		WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND ti.search @@ q
+		ORDER BY ti.id, li.list_id)
+	) r, websearch_to_tsquery('english', $2) q
+	WHERE %[2]s %[3]s`, headlineOptions, cond, order, escapeHTML("r.title"), escapeHTML("coalesce(r.description, '')"))
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, append([]interface{}{userId, query}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res models.SearchResult
+		if err := rows.Scan(&res.Type, &res.ID, &res.ListID, &res.Title, &res.Snippet, &res.Rank); err != nil {
+			return nil, nil, err
+		}
+		results = append(results, &res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	results, info := paginate(results, page, func(res *models.SearchResult) *models.Cursor {
+		uid := int64(res.ID) << 1
+		if res.Type == models.SearchItem {
+			uid |= 1
+		}
+		return &models.Cursor{Key: strconv.FormatFloat(float64(res.Rank), 'g', -1, 32), ID: uid}
+	})
+
+	return results, info, nil
+}
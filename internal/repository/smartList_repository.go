@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"fmt"
+)
+
+type SmartListPostgres struct {
+	db DB
+}
+
+func NewSmartListPostgres(db DB) *SmartListPostgres {
+	return &SmartListPostgres{db: db}
+}
+
+func (r *SmartListPostgres) Create(ctx context.Context, userId int, input *models.SmartListInput) (*models.SmartList, error) {
+	list := &models.SmartList{UserID: userId, Title: input.Title, Query: input.Query, Shared: input.Shared}
+
+	err := r.db.QueryRowContext(ctx, "INSERT INTO smart_lists (user_id, title, query, shared) VALUES ($1, $2, $3, $4) RETURNING id",
+		userId, input.Title, input.Query, input.Shared).Scan(&list.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+var smartListKeyset = keyset{key: "title", id: "id"}
+
+// GetAll returns the user's own smart lists and those shared with the user.
+func (r *SmartListPostgres) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.SmartList, *models.PageInfo, error) {
+	lists := make([]*models.SmartList, 0)
+
+	cond, order, args, err := smartListKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf("SELECT id, user_id, title, query, shared FROM smart_lists WHERE %s AND %s %s", visibleTo(1), cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var list models.SmartList
+		if err := rows.Scan(&list.ID, &list.UserID, &list.Title, &list.Query, &list.Shared); err != nil {
+			return nil, nil, err
+		}
+		lists = append(lists, &list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	lists, info := paginate(lists, page, func(l *models.SmartList) *models.Cursor {
+		return &models.Cursor{Key: l.Title, ID: int64(l.ID)}
+	})
+
+	return lists, info, nil
+}
+
+func (r *SmartListPostgres) GetById(ctx context.Context, userId, smartListId int) (*models.SmartList, error) {
+	list := &models.SmartList{}
+
+	query := "SELECT id, user_id, title, query, shared FROM smart_lists WHERE id = $1 AND " + visibleTo(2)
+	err := r.db.QueryRowContext(ctx, query, smartListId, userId).Scan(&list.ID, &list.UserID, &list.Title, &list.Query, &list.Shared)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *SmartListPostgres) Update(ctx context.Context, userId, smartListId int, input *models.SmartListInput) error {
+	query := "UPDATE smart_lists SET title = $1, query = $2, shared = $3 WHERE id = $4 AND user_id = $5"
+
+	return requireRows(r.db.ExecContext(ctx, query, input.Title, input.Query, input.Shared, smartListId, userId))
+}
+
+func (r *SmartListPostgres) Delete(ctx context.Context, userId, smartListId int) error {
+	return requireRows(r.db.ExecContext(ctx, "DELETE FROM smart_lists WHERE id = $1 AND user_id = $2", smartListId, userId))
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"time"
+)
+
+type SyncPostgres struct {
+	db DB
+}
+
+func NewSyncPostgres(db DB) *SyncPostgres {
+	return &SyncPostgres{db: db}
+}
+
+// Horizon returns the oldest transaction that is still running. Every change
+// of the transactions below it is visible to the queries that follow.
+func (r *SyncPostgres) Horizon(ctx context.Context) (int64, error) {
+	var xid int64
+	err := r.db.QueryRowContext(ctx, "SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint").Scan(&xid)
+	return xid, err
+}
+
+// Changes returns up to limit lists and items of the user that transactions
+// from token.From to token.Until changed, lists first, after the key in the
+// token. Those that changed include the ones that were removed or became
+// visible to the user; tombstones are only read for a token that isn't full.
+// The kinds are those of models.SyncKindList and models.SyncKindItem.
+func (r *SyncPostgres) Changes(ctx context.Context, userId int, token *models.SyncToken, limit int) ([]*models.SyncKey, error) {
+	keys := make([]*models.SyncKey, 0)
+
+	query := `SELECT c.kind, c.id FROM (
+		SELECT 0 AS kind, tl.id FROM todo_lists tl INNER JOIN users_lists ul ON ul.list_id = tl.id
+		WHERE ul.user_id = $1 AND (tl.sync_xid >= $2 AND tl.sync_xid < $3 OR ul.created_xid >= $2 AND ul.created_xid < $3)
+		UNION
+		SELECT 0, t.entity_id FROM sync_tombstones t
+		WHERE $2 > 0 AND t.entity = 'list' AND t.user_id = $1 AND t.sync_xid >= $2 AND t.sync_xid < $3
+		UNION
+		SELECT 1, ti.id FROM todo_items ti INNER JOIN lists_items li ON li.item_id = ti.id
+		INNER JOIN todo_lists tl ON tl.id = li.list_id INNER JOIN users_lists ul ON ul.list_id = li.list_id
+		WHERE ul.user_id = $1 AND (ti.sync_xid >= $2 AND ti.sync_xid < $3 OR tl.visible_xid >= $2 AND tl.visible_xid < $3
+		OR ul.created_xid >= $2 AND ul.created_xid < $3)
+		UNION
+		SELECT 1, t.entity_id FROM sync_tombstones t INNER JOIN users_lists ul ON ul.list_id = t.list_id
+		WHERE $2 > 0 AND t.entity = 'item' AND ul.user_id = $1 AND t.sync_xid >= $2 AND t.sync_xid < $3
+	) c WHERE (c.kind, c.id) > ($4, $5) ORDER BY c.kind, c.id LIMIT $6`
+
+	rows, err := r.db.QueryContext(ctx, query, userId, token.From, token.Until, token.Kind, token.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key models.SyncKey
+		if err := rows.Scan(&key.Kind, &key.ID); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Purge removes the tombstones created before the given time.
+func (r *SyncPostgres) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM sync_tombstones WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
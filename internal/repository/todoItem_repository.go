@@ -2,62 +2,78 @@ package repository
 
 import (
 	"Todo-app/internal/models"
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type TodoItemPostgres struct {
-	db *sql.DB
+	db DB
 }
 
-func NewTodoItemPostgres(db *sql.DB) *TodoItemPostgres {
+func NewTodoItemPostgres(db DB) *TodoItemPostgres {
 	return &TodoItemPostgres{db: db}
 }
 
-func (r *TodoItemPostgres) Create(listId int, item *models.ToDoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemPostgres) Create(ctx context.Context, listId int, item *models.ToDoItem) (int, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
+	defer tx.Rollback()
 
-	var itemId int
-	createItemQuery := fmt.Sprintf("INSERT INTO todo_items (title, description) values ($1, $2) RETURNING id")
+	if err := lockList(ctx, tx, listId); err != nil {
+		return 0, err
+	}
 
-	row := tx.QueryRow(createItemQuery, item.Title, item.Description)
-	err = row.Scan(&itemId)
+	item.Position, err = lastPosition(ctx, tx, "lists_items", "list_id", listId)
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return 0, err
-		}
 		return 0, err
 	}
 
-	createListItemsQuery := fmt.Sprintf("INSERT INTO lists_items (list_id, item_id) values ($1, $2)")
-	_, err = tx.Exec(createListItemsQuery, listId, itemId)
+	itemId, err := insertItem(ctx, tx, listId, item)
 	if err != nil {
-		err := tx.Rollback()
-		if err != nil {
-			return 0, err
-		}
 		return 0, err
 	}
 
 	return itemId, tx.Commit()
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]*models.ToDoItem, error) {
+// insertItem creates an item and links it to listId at item.Position.
+func insertItem(ctx context.Context, tx Tx, listId int, item *models.ToDoItem) (int, error) {
+	var itemId int
+	createItemQuery := fmt.Sprintf("INSERT INTO todo_items (title, description, done, due, labels, priority, assignee_id) values ($1, $2, $3, $4, $5, $6, $7) RETURNING id")
+
+	row := tx.QueryRowContext(ctx, createItemQuery, item.Title, item.Description, item.Done, item.Due, labelsArray(item.Labels), item.Priority, item.AssigneeID)
+	if err := row.Scan(&itemId); err != nil {
+		return 0, err
+	}
+
+	createListItemsQuery := fmt.Sprintf("INSERT INTO lists_items (list_id, item_id, position) values ($1, $2, $3)")
+	if _, err := tx.ExecContext(ctx, createListItemsQuery, listId, itemId, item.Position); err != nil {
+		return 0, err
+	}
+
+	return itemId, nil
+}
+
+// listItems returns every item of a list in order, without access checks.
+func listItems(ctx context.Context, tx Tx, listId int) ([]*models.ToDoItem, error) {
 	var items []*models.ToDoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
-									INNER JOIN users_lists ul on ul.list_id = li.list_id WHERE li.list_id = $1 AND ul.user_id = $2`)
-	rows, err := r.db.Query(query, listId, userId)
+	query := `SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id FROM todo_items ti
+	INNER JOIN lists_items li on li.item_id = ti.id WHERE li.list_id = $1 AND ti.deleted_at IS NULL ORDER BY li.position, ti.id`
+	rows, err := tx.QueryContext(ctx, query, listId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var item models.ToDoItem
-		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done); err != nil {
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID); err != nil {
 			return nil, err
 		}
 		items = append(items, &item)
@@ -68,27 +84,567 @@ func (r *TodoItemPostgres) GetAll(userId, listId int) ([]*models.ToDoItem, error
 	return items, nil
 }
 
-func (r *TodoItemPostgres) GetById(userId, itemId int) (*models.ToDoItem, error) {
+func labelsArray(labels []string) interface{} {
+	if labels == nil {
+		labels = []string{}
+	}
+
+	return pq.Array(labels)
+}
+
+var itemKeyset = keyset{key: "li.position", id: "ti.id"}
+
+func (r *TodoItemPostgres) GetAll(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error) {
+	items := make([]*models.ToDoItem, 0)
+	cond, order, args, err := itemKeyset.clause(page, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+									INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+									WHERE li.list_id = $1 AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+									AND %s %s`, cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{listId, userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item models.ToDoItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	items, info := paginate(items, page, func(i *models.ToDoItem) *models.Cursor {
+		return &models.Cursor{Key: i.Position, ID: int64(i.ID)}
+	})
+	return items, info, nil
+}
+
+// GetFirstPages returns the first page of items of each of the given lists
+// the user can access, in one query. Lists without items are left out.
+func (r *TodoItemPostgres) GetFirstPages(ctx context.Context, userId int, listIds []int, limit int) (map[int]*models.ItemPage, error) {
+	page := &models.PageRequest{Limit: limit}
+	query := `SELECT id, title, description, done, due, labels, priority, assignee_id, version, position, list_id FROM (
+		SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id,
+		row_number() OVER (PARTITION BY li.list_id ORDER BY li.position, ti.id) AS n
+		FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+		INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+		WHERE li.list_id = ANY($1) AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+	) i WHERE n <= $3 ORDER BY list_id, position, id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(listIds), userId, page.Size()+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make(map[int][]*models.ToDoItem)
+	for rows.Next() {
+		var item models.ToDoItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID); err != nil {
+			return nil, err
+		}
+		items[item.ListID] = append(items[item.ListID], &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pages := make(map[int]*models.ItemPage, len(items))
+	for listId, listItems := range items {
+		listItems, info := paginate(listItems, page, func(i *models.ToDoItem) *models.Cursor {
+			return &models.Cursor{Key: i.Position, ID: int64(i.ID)}
+		})
+		pages[listId] = &models.ItemPage{Items: listItems, Info: info}
+	}
+
+	return pages, nil
+}
+
+// Find returns the items matching a filter across all lists the user can
+// access, leaving out trashed items and trashed or archived lists.
+func (r *TodoItemPostgres) Find(ctx context.Context, userId int, filter *models.ItemFilter, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error) {
+	items := make([]*models.ToDoItem, 0)
+
+	where, args := filterCondition(filter, 2)
+	cond, order, pageArgs, err := itemSortKeyset(filter).clause(page, 2+len(args))
+	if err != nil {
+		return nil, nil, err
+	}
+	// an item in several matching lists is returned once, with the first of
+	// them, so that it appears on one page only
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, ti.position, ti.list_id FROM (
+		SELECT DISTINCT ON (ti.id) ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id FROM todo_items ti
+		INNER JOIN lists_items li on li.item_id = ti.id INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+		WHERE ul.user_id = $1 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL AND tl.archived_at IS NULL AND %s
+		ORDER BY ti.id, li.list_id
+	) ti WHERE %s %s`, where, cond, order)
+
+	args = append(append([]interface{}{userId}, args...), pageArgs...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ToDoItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	items, info := paginate(items, page, itemSortCursor(filter))
+	return items, info, nil
+}
+
+func (r *TodoItemPostgres) GetById(ctx context.Context, userId, itemId int) (*models.ToDoItem, error) {
 	var item models.ToDoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
-									INNER JOIN users_lists ul on ul.list_id = li.list_id WHERE ti.id = $1 AND ul.user_id = $2`)
-	err := r.db.QueryRow(query, itemId, userId).Scan(&item.ID, &item.Title, &item.Description, &item.Done)
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+									INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+									WHERE ti.id = $1 AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL`)
+	err := r.db.QueryRowContext(ctx, query, itemId, userId).Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID)
 	if err != nil {
 		return nil, err
 	}
 	return &item, nil
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
-	query := fmt.Sprintf(`DELETE FROM todo_items ti USING lists_items li, users_lists ul 
-									WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2`)
-	_, err := r.db.Exec(query, userId, itemId)
-	return err
+func (r *TodoItemPostgres) GetByIds(ctx context.Context, userId int, itemIds []int) ([]*models.ToDoItem, error) {
+	items := make([]*models.ToDoItem, 0, len(itemIds))
+
+	query := `SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id FROM todo_items ti INNER JOIN lists_items li on li.item_id = ti.id
+									INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+									WHERE ti.id = ANY($1) AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(itemIds), userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ToDoItem
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version, &item.Position, &item.ListID); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
+
+// Delete moves an item to the trash. A non-nil version makes the change
+// conditional on the item still being at that version.
+func (r *TodoItemPostgres) Delete(ctx context.Context, userId, itemId int, version *int) error {
+	query := fmt.Sprintf(`UPDATE todo_items ti SET deleted_at = now() FROM lists_items li, users_lists ul
+									WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2 AND ti.deleted_at IS NULL
+									AND ($3::int IS NULL OR ti.version = $3)`)
+	err := requireRows(r.db.ExecContext(ctx, query, userId, itemId, version))
+	return checkVersion(err, version, r.exists(ctx, userId, itemId))
+}
+
+func (r *TodoItemPostgres) exists(ctx context.Context, userId, itemId int) func() error {
+	return func() error {
+		_, err := r.GetById(ctx, userId, itemId)
+		return err
+	}
+}
+
+func (r *TodoItemPostgres) Restore(ctx context.Context, userId, itemId int) error {
+	query := `UPDATE todo_items ti SET deleted_at = NULL FROM lists_items li, users_lists ul, todo_lists tl
+	WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND tl.id = li.list_id AND ul.user_id = $1 AND ti.id = $2
+	AND ti.deleted_at IS NOT NULL AND tl.deleted_at IS NULL`
+	return requireRows(r.db.ExecContext(ctx, query, userId, itemId))
+}
+
+// Reorder moves an item within one of its lists, which an item in several
+// lists has to be told.
+func (r *TodoItemPostgres) Reorder(ctx context.Context, userId, listId, itemId int, input *models.PositionInput) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found int
+	query := `SELECT li.list_id FROM lists_items li INNER JOIN users_lists ul on ul.list_id = li.list_id
+	INNER JOIN todo_items ti on ti.id = li.item_id WHERE li.item_id = $1 AND li.list_id = $2 AND ul.user_id = $3 AND ti.deleted_at IS NULL`
+	if err := tx.QueryRowContext(ctx, query, itemId, listId, userId).Scan(&found); err != nil {
+		return err
+	}
+
+	if err := lockList(ctx, tx, listId); err != nil {
+		return err
+	}
+
+	position, err := nearPosition(ctx, tx, "lists_items", "list_id", "item_id", listId, itemId, input)
+	if err != nil {
+		return err
+	}
+
+	if err := setPosition(ctx, tx, "lists_items", "list_id", "item_id", listId, itemId, position); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Place puts an item back into a list at a position it had before.
+func (r *TodoItemPostgres) Place(ctx context.Context, userId, itemId, listId int, position string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sources, err := lockTransfer(ctx, tx, userId, &models.TransferInput{ItemIDs: []int{itemId}, ListID: listId})
+	if err != nil {
+		return err
+	}
+
+	taken, err := positionTaken(ctx, tx, "lists_items", "list_id", "item_id", listId, itemId, position)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return ErrConflict
+	}
+
+	lists := sources[itemId]
+	from := lists[0]
+	if hasList(lists, listId) {
+		from = listId
+	}
+
+	if err := placeItem(ctx, tx, itemId, from, listId, position); err != nil {
+		return err
+	}
+
+	if err := leaveLists(ctx, tx, itemId, lists, listId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input *models.UpdateItemInput) error {
-	query := `UPDATE todo_items ti SET title = $1, description = $2, done = $3 FROM lists_items li, users_lists ul
-	WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $4 AND ti.id = $5`
-	_, err := r.db.Exec(query, input.Title, input.Description, input.Done, userId, itemId)
+// Update sets the fields of the input that are given and, with ClearDue and
+// ClearAssignee, removes the due date and the assignee.
+func (r *TodoItemPostgres) Update(ctx context.Context, userId, itemId int, input *models.UpdateItemInput) error {
+	setQuery, args := itemUpdateSet(input)
+
+	argId := len(args) + 1
+	query := fmt.Sprintf(`UPDATE todo_items ti SET %s
+	FROM lists_items li, users_lists ul
+	WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d AND ti.deleted_at IS NULL
+	AND ($%d::int IS NULL OR ti.version = $%d)`, setQuery, argId, argId+1, argId+2, argId+2)
+	args = append(args, userId, itemId, input.Version)
+
+	err := requireRows(r.db.ExecContext(ctx, query, args...))
+	return checkVersion(err, input.Version, r.exists(ctx, userId, itemId))
+}
+
+// BulkUpdate makes the same update to several items. It changes nothing and
+// returns sql.ErrNoRows unless the user can access every one of them.
+func (r *TodoItemPostgres) BulkUpdate(ctx context.Context, userId int, itemIds []int, input *models.UpdateItemInput) error {
+	setQuery, args := itemUpdateSet(input)
+
+	argId := len(args) + 1
+	query := fmt.Sprintf(`UPDATE todo_items ti SET %s
+	FROM lists_items li, users_lists ul
+	WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = ANY($%d) AND ti.deleted_at IS NULL`,
+		setQuery, argId, argId+1)
+	args = append(args, userId, pq.Array(itemIds))
+
+	return r.bulk(ctx, itemIds, query, args...)
+}
+
+// BulkDelete moves several items to the trash, all or none of them.
+func (r *TodoItemPostgres) BulkDelete(ctx context.Context, userId int, itemIds []int) error {
+	query := `UPDATE todo_items ti SET deleted_at = now()
+	FROM lists_items li, users_lists ul
+	WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = ANY($2) AND ti.deleted_at IS NULL`
+
+	return r.bulk(ctx, itemIds, query, userId, pq.Array(itemIds))
+}
+
+// bulk runs a statement that changes the given items and rolls it back when
+// it missed any of them.
+func (r *TodoItemPostgres) bulk(ctx context.Context, itemIds []int, query string, args ...interface{}) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	unique := make(map[int]bool, len(itemIds))
+	for _, id := range itemIds {
+		unique[id] = true
+	}
+
+	if n != int64(len(unique)) {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// itemUpdateSet returns the SET clause of an item update and its arguments,
+// numbered from $1.
+func itemUpdateSet(input *models.UpdateItemInput) (string, []interface{}) {
+	setValues := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		setValues = append(setValues, fmt.Sprintf("%s=$%d", column, len(args)))
+	}
+
+	if input.Title != nil {
+		set("title", *input.Title)
+	}
+	if input.Description != nil {
+		set("description", *input.Description)
+	}
+	if input.Done != nil {
+		set("done", *input.Done)
+	}
+	if input.Due != nil {
+		set("due", *input.Due)
+	} else if input.ClearDue {
+		setValues = append(setValues, "due=NULL")
+	}
+	if input.Labels != nil {
+		set("labels", labelsArray(*input.Labels))
+	}
+	if input.Priority != nil {
+		set("priority", *input.Priority)
+	}
+	if input.AssigneeID != nil {
+		set("assignee_id", *input.AssigneeID)
+	} else if input.ClearAssignee {
+		setValues = append(setValues, "assignee_id=NULL")
+	}
+
+	return strings.Join(setValues, ", "), args
+}
+
+func (r *TodoItemPostgres) Move(ctx context.Context, userId int, input *models.TransferInput) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sources, err := lockTransfer(ctx, tx, userId, input)
+	if err != nil {
+		return err
+	}
+
+	for _, itemId := range input.ItemIDs {
+		lists := sources[itemId]
+		if !hasList(lists, input.ListID) {
+			position, err := lastPosition(ctx, tx, "lists_items", "list_id", input.ListID)
+			if err != nil {
+				return err
+			}
+
+			if err := placeItem(ctx, tx, itemId, lists[0], input.ListID, position); err != nil {
+				return err
+			}
+		}
+
+		if err := leaveLists(ctx, tx, itemId, lists, input.ListID); err != nil {
+			return err
+		}
+
+		sources[itemId] = []int{input.ListID}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TodoItemPostgres) Copy(ctx context.Context, userId int, input *models.TransferInput) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockTransfer(ctx, tx, userId, input); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(input.ItemIDs))
+	for _, itemId := range input.ItemIDs {
+		var id int
+		err := tx.QueryRowContext(ctx, `INSERT INTO todo_items (title, description, done, due, labels, priority, assignee_id)
+		SELECT title, description, done, due, labels, priority, assignee_id FROM todo_items WHERE id = $1 RETURNING id`, itemId).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		position, err := lastPosition(ctx, tx, "lists_items", "list_id", input.ListID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO lists_items (list_id, item_id, position) VALUES ($1, $2, $3)", input.ListID, id, position)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, tx.Commit()
+}
+
+// lockTransfer checks that the user can access the target list and every
+// item, then locks the target and the items' lists in one pass in id order,
+// so that concurrent transfers don't deadlock. An item can move before its
+// lists are locked, so the items' lists are read again under the locks and
+// any new ones locked too, until none turns up. Those later locks can still
+// deadlock with another transfer, which Postgres breaks by failing one of
+// them for Transact to retry. It returns every list of the user each item
+// belongs to.
+func lockTransfer(ctx context.Context, tx Tx, userId int, input *models.TransferInput) (map[int][]int, error) {
+	var target int
+	err := tx.QueryRowContext(ctx, `SELECT ul.list_id FROM users_lists ul INNER JOIN todo_lists tl on tl.id = ul.list_id
+	WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL`, userId, input.ListID).Scan(&target)
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := transferSources(ctx, tx, userId, input.ItemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[int]bool)
+	pending := []int{target}
+	for _, lists := range sources {
+		for _, id := range lists {
+			if !hasList(pending, id) {
+				pending = append(pending, id)
+			}
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		sort.Ints(pending)
+		for _, id := range pending {
+			if err := lockList(ctx, tx, id); err != nil {
+				return nil, err
+			}
+			locked[id] = true
+		}
+
+		if sources, err = transferSources(ctx, tx, userId, input.ItemIDs); err != nil {
+			return nil, err
+		}
+
+		pending = pending[:0]
+		for _, lists := range sources {
+			for _, id := range lists {
+				if !locked[id] && !hasList(pending, id) {
+					pending = append(pending, id)
+				}
+			}
+		}
+
+		if len(pending) == 0 {
+			return sources, nil
+		}
+
+		if attempt == transferLockAttempts {
+			return nil, ErrConflict
+		}
+	}
+}
+
+// transferLockAttempts bounds how often lockTransfer chases items that keep
+// moving into lists it hasn't locked yet.
+const transferLockAttempts = 3
+
+// transferSources returns the lists of the user each item belongs to, in id
+// order. It fails with sql.ErrNoRows when an item isn't in any of them.
+func transferSources(ctx context.Context, tx Tx, userId int, itemIds []int) (map[int][]int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT li.item_id, li.list_id FROM lists_items li INNER JOIN users_lists ul on ul.list_id = li.list_id
+	INNER JOIN todo_items ti on ti.id = li.item_id INNER JOIN todo_lists tl on tl.id = li.list_id
+	WHERE li.item_id = ANY($1) AND ul.user_id = $2 AND ti.deleted_at IS NULL AND tl.deleted_at IS NULL
+	ORDER BY li.item_id, li.list_id`, pq.Array(itemIds), userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := make(map[int][]int)
+	for rows.Next() {
+		var itemId, listId int
+		if err := rows.Scan(&itemId, &listId); err != nil {
+			return nil, err
+		}
+		sources[itemId] = append(sources[itemId], listId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, itemId := range itemIds {
+		if _, ok := sources[itemId]; !ok {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	return sources, nil
+}
+
+// placeItem moves an item's membership of list from into list to.
+func placeItem(ctx context.Context, tx Tx, itemId, from, to int, position string) error {
+	return requireRows(tx.ExecContext(ctx, "UPDATE lists_items SET list_id = $1, position = $2 WHERE item_id = $3 AND list_id = $4",
+		to, position, itemId, from))
+}
+
+// leaveLists removes an item from every one of lists except keep, so an item
+// that was in several of the user's lists ends up only in the one it was
+// moved to.
+func leaveLists(ctx context.Context, tx Tx, itemId int, lists []int, keep int) error {
+	if len(lists) < 2 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, "DELETE FROM lists_items WHERE item_id = $1 AND list_id = ANY($2) AND list_id <> $3",
+		itemId, pq.Array(lists), keep)
 	return err
 }
+
+func hasList(lists []int, listId int) bool {
+	for _, id := range lists {
+		if id == listId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,45 +2,127 @@ package repository
 
 import (
 	"Todo-app/internal/models"
-	"database/sql"
+	"context"
 	"fmt"
 	"strings"
+
+	"github.com/lib/pq"
 )
 
 type TodoListPostgres struct {
-	db *sql.DB
+	db DB
 }
 
-func NewTodoListPostgres(db *sql.DB) *TodoListPostgres {
+func NewTodoListPostgres(db DB) *TodoListPostgres {
 	return &TodoListPostgres{db: db}
 }
 
-func (r *TodoListPostgres) Create(userId int, list *models.ToDoList) (int, error) {
+func (r *TodoListPostgres) Create(ctx context.Context, userId int, list *models.ToDoList) (int, error) {
 	if err := list.Validate(); err != nil {
 		return 0, err
 	}
 
-	err := r.db.QueryRow("INSERT INTO todo_lists (title, description) VALUES ($1, $2) RETURNING id",
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := insertList(ctx, tx, userId, list); err != nil {
+		return 0, err
+	}
+
+	return list.ID, tx.Commit()
+}
+
+func (r *TodoListPostgres) Duplicate(ctx context.Context, userId, listId int, title string) (*models.ToDoList, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	list := &models.ToDoList{}
+	query := "SELECT tl.title, tl.description FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL"
+	if err := tx.QueryRowContext(ctx, query, userId, listId).Scan(&list.Title, &list.Description); err != nil {
+		return nil, err
+	}
+
+	if title != "" {
+		list.Title = title
+	}
+
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+
+	items, err := listItems(ctx, tx, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertList(ctx, tx, userId, list); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.ID, err = insertItem(ctx, tx, list.ID, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return list, tx.Commit()
+}
+
+// insertList creates a list and appends it to the end of the user's lists.
+func insertList(ctx context.Context, tx Tx, userId int, list *models.ToDoList) error {
+	if err := lockUser(ctx, tx, userId); err != nil {
+		return err
+	}
+
+	err := tx.QueryRowContext(ctx, "INSERT INTO todo_lists (title, description) VALUES ($1, $2) RETURNING id",
 		list.Title,
 		list.Description,
 	).Scan(&list.ID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	_, err = r.db.Exec("INSERT INTO users_lists (user_id, list_id) VALUES ($1, $2)", userId, list.ID)
+	list.Position, err = lastPosition(ctx, tx, "users_lists", "user_id", userId)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	return list.ID, nil
+	_, err = tx.ExecContext(ctx, "INSERT INTO users_lists (user_id, list_id, position) VALUES ($1, $2, $3)", userId, list.ID, list.Position)
+	return err
+}
+
+var listKeyset = keyset{key: "ul.position", id: "tl.id"}
+
+func (r *TodoListPostgres) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error) {
+	return r.getPage(ctx, userId, "tl.archived_at IS NULL", page)
+}
+
+func (r *TodoListPostgres) GetById(ctx context.Context, userId, listId int) (*models.ToDoList, error) {
+	list := &models.ToDoList{}
+
+	query := "SELECT tl.id, tl.title, tl.description, tl.version, ul.position FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2 AND tl.deleted_at IS NULL"
+	err := r.db.QueryRowContext(ctx, query, userId, listId).Scan(&list.ID, &list.Title, &list.Description, &list.Version, &list.Position)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
 
-func (r *TodoListPostgres) GetAll(userId int) ([]*models.ToDoList, error) {
-	var lists []*models.ToDoList
+// GetByIds returns the lists among listIds that the user can access, in no
+// particular order.
+func (r *TodoListPostgres) GetByIds(ctx context.Context, userId int, listIds []int) ([]*models.ToDoList, error) {
+	lists := make([]*models.ToDoList, 0, len(listIds))
 
-	query := "SELECT tl.id, tl.title, tl.description FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1"
-	rows, err := r.db.Query(query, userId)
+	query := "SELECT tl.id, tl.title, tl.description, tl.version, ul.position FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = ANY($2) AND tl.deleted_at IS NULL"
+	rows, err := r.db.QueryContext(ctx, query, userId, pq.Array(listIds))
 	if err != nil {
 		return nil, err
 	}
@@ -48,40 +130,140 @@ func (r *TodoListPostgres) GetAll(userId int) ([]*models.ToDoList, error) {
 
 	for rows.Next() {
 		var list models.ToDoList
-		if err := rows.Scan(&list.ID, &list.Title, &list.Description); err != nil {
+		if err := rows.Scan(&list.ID, &list.Title, &list.Description, &list.Version, &list.Position); err != nil {
 			return nil, err
 		}
 		lists = append(lists, &list)
 	}
 
+	return lists, rows.Err()
+}
+
+// Delete moves a list to the trash. A non-nil version makes the change
+// conditional on the list still being at that version.
+func (r *TodoListPostgres) Delete(ctx context.Context, userId, listId int, version *int) error {
+	query := `UPDATE todo_lists tl SET deleted_at = now() FROM users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2 AND tl.deleted_at IS NULL
+	AND ($3::int IS NULL OR tl.version = $3)`
+
+	err := requireRows(r.db.ExecContext(ctx, query, userId, listId, version))
+	return checkVersion(err, version, r.exists(ctx, userId, listId))
+}
+
+func (r *TodoListPostgres) exists(ctx context.Context, userId, listId int) func() error {
+	return func() error {
+		_, err := r.GetById(ctx, userId, listId)
+		return err
+	}
+}
+
+func (r *TodoListPostgres) Restore(ctx context.Context, userId, listId int) error {
+	query := "UPDATE todo_lists tl SET deleted_at = NULL FROM users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2 AND tl.deleted_at IS NOT NULL"
+
+	return requireRows(r.db.ExecContext(ctx, query, userId, listId))
+}
+
+func (r *TodoListPostgres) Archive(ctx context.Context, userId, listId int) error {
+	query := "UPDATE todo_lists tl SET archived_at = now() FROM users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2 AND tl.deleted_at IS NULL AND tl.archived_at IS NULL"
+
+	return requireRows(r.db.ExecContext(ctx, query, userId, listId))
+}
+
+func (r *TodoListPostgres) Unarchive(ctx context.Context, userId, listId int) error {
+	query := "UPDATE todo_lists tl SET archived_at = NULL FROM users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2 AND tl.deleted_at IS NULL AND tl.archived_at IS NOT NULL"
+
+	return requireRows(r.db.ExecContext(ctx, query, userId, listId))
+}
+
+func (r *TodoListPostgres) GetArchived(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error) {
+	return r.getPage(ctx, userId, "tl.archived_at IS NOT NULL", page)
+}
+
+func (r *TodoListPostgres) getPage(ctx context.Context, userId int, filter string, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error) {
+	lists := make([]*models.ToDoList, 0)
+
+	cond, order, args, err := listKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.version, ul.position FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id
+	WHERE ul.user_id = $1 AND tl.deleted_at IS NULL AND %s AND %s %s`, filter, cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var list models.ToDoList
+		if err := rows.Scan(&list.ID, &list.Title, &list.Description, &list.Version, &list.Position); err != nil {
+			return nil, nil, err
+		}
+		lists = append(lists, &list)
+	}
+
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return lists, nil
+	lists, info := paginate(lists, page, func(l *models.ToDoList) *models.Cursor {
+		return &models.Cursor{Key: l.Position, ID: int64(l.ID)}
+	})
+
+	return lists, info, nil
 }
 
-func (r *TodoListPostgres) GetById(userId, listId int) (*models.ToDoList, error) {
-	list := &models.ToDoList{}
+func (r *TodoListPostgres) Reorder(ctx context.Context, userId, listId int, input *models.PositionInput) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	query := "SELECT tl.id, tl.title, tl.description FROM todo_lists tl INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2"
-	err := r.db.QueryRow(query, userId, listId).Scan(&list.ID, &list.Title, &list.Description)
+	if err := lockUser(ctx, tx, userId); err != nil {
+		return err
+	}
 
+	position, err := nearPosition(ctx, tx, "users_lists", "user_id", "list_id", userId, listId, input)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return list, nil
+	if err := setPosition(ctx, tx, "users_lists", "user_id", "list_id", userId, listId, position); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoListPostgres) Delete(userId, listId int) error {
-	query := "DELETE FROM todo_lists tl USING users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2"
-	_, err := r.db.Exec(query, userId, listId)
+// Place puts a list back at a position it had before.
+func (r *TodoListPostgres) Place(ctx context.Context, userId, listId int, position string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return err
+	if err := lockUser(ctx, tx, userId); err != nil {
+		return err
+	}
+
+	taken, err := positionTaken(ctx, tx, "users_lists", "user_id", "list_id", userId, listId, position)
+	if err != nil {
+		return err
+	}
+
+	if taken {
+		return ErrConflict
+	}
+
+	if err := setPosition(ctx, tx, "users_lists", "user_id", "list_id", userId, listId, position); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoListPostgres) Update(userId, listId int, input *models.UpdateListInput) error {
+func (r *TodoListPostgres) Update(ctx context.Context, userId, listId int, input *models.UpdateListInput) error {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argId := 1
@@ -100,9 +282,10 @@ func (r *TodoListPostgres) Update(userId, listId int, input *models.UpdateListIn
 
 	setQuery := strings.Join(setValues, ", ")
 
-	query := fmt.Sprintf("UPDATE todo_lists tl FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id=$%d AND ul.user_id=$%d", setQuery, argId, argId+1)
-	args = append(args, userId, listId)
+	query := fmt.Sprintf(`UPDATE todo_lists tl SET %s FROM users_lists ul WHERE tl.id = ul.list_id AND ul.user_id=$%d AND ul.list_id=$%d AND tl.deleted_at IS NULL
+	AND ($%d::int IS NULL OR tl.version = $%d)`, setQuery, argId, argId+1, argId+2, argId+2)
+	args = append(args, userId, listId, input.Version)
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	err := requireRows(r.db.ExecContext(ctx, query, args...))
+	return checkVersion(err, input.Version, r.exists(ctx, userId, listId))
 }
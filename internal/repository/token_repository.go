@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+)
+
+type TokenPostgres struct {
+	db DB
+}
+
+func NewTokenPostgres(db DB) *TokenPostgres {
+	return &TokenPostgres{db: db}
+}
+
+func (r *TokenPostgres) Create(ctx context.Context, token *models.APIToken, hash []byte) error {
+	return r.db.QueryRowContext(ctx, "INSERT INTO api_tokens (user_id, name, token_hash) VALUES ($1, $2, $3) RETURNING id, created_at",
+		token.UserID, token.Name, hash,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *TokenPostgres) GetAll(ctx context.Context, userId int) ([]*models.APIToken, error) {
+	tokens := make([]*models.APIToken, 0)
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, user_id, name, created_at FROM api_tokens WHERE user_id = $1 ORDER BY id", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var token models.APIToken
+		if err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// FindUser returns the owner of the token with the given hash.
+func (r *TokenPostgres) FindUser(ctx context.Context, hash []byte) (*models.User, error) {
+	user := &models.User{}
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT u.id, u.name, u.email, u.is_admin, u.time_zone FROM api_tokens t
+		INNER JOIN users u ON u.id = t.user_id WHERE t.token_hash = $1`,
+		hash,
+	).Scan(&user.ID, &user.Name, &user.Email, &user.IsAdmin, &user.TimeZone); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *TokenPostgres) Delete(ctx context.Context, userId, tokenId int) error {
+	return requireRows(r.db.ExecContext(ctx, "DELETE FROM api_tokens WHERE id = $1 AND user_id = $2", tokenId, userId))
+}
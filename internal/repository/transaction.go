@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+const (
+	// maxTxAttempts is how often a transaction is run before a serialization
+	// failure is returned.
+	maxTxAttempts = 4
+	// txBackoff is the longest wait before the second attempt; it doubles
+	// with every further attempt.
+	txBackoff = 20 * time.Millisecond
+)
+
+type txKey struct{}
+
+// Transact calls fn with repositories that share one transaction, which is
+// committed when fn returns nil and rolled back otherwise. The context fn
+// gets carries the transaction: Transact called with it, or on the
+// repositories of a transaction, joins that transaction in a savepoint, so
+// that a failing part is rolled back alone while the outermost call decides
+// about the whole.
+//
+// Transactions are serializable: a change reads the rows it is about to
+// write, for its checks, versions and audit entry, and a concurrent change to
+// them in between has to abort it rather than be overwritten or recorded
+// against a stale snapshot. A transaction that fails because Postgres
+// couldn't serialize it with a concurrent one, or picked it to break a
+// deadlock, is run again from the start after a short random wait, so fn must
+// not have effects outside the transaction that a second run would repeat.
+func (r *Repository) Transact(ctx context.Context, fn func(ctx context.Context, repos *Repository) error) error {
+	if repos, ok := ctx.Value(txKey{}).(*Repository); ok {
+		r = repos
+	}
+
+	if r.tx != nil {
+		return r.join(ctx, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.transact(ctx, fn)
+		if !Retryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		wait := time.Duration(rand.Int63n(int64(txBackoff << (attempt - 1))))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *Repository) transact(ctx context.Context, fn func(ctx context.Context, repos *Repository) error) error {
+	tx, err := r.pool.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	shared := &sharedTx{Tx: tx}
+	repos := newRepository(shared)
+	repos.tx = shared
+
+	if err := fn(context.WithValue(ctx, txKey{}, repos), repos); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) join(ctx context.Context, fn func(ctx context.Context, repos *Repository) error) error {
+	sp, err := r.tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, r), r); err != nil {
+		return err
+	}
+
+	return sp.Commit()
+}
+
+// Retryable reports whether a transaction failed only because of a
+// concurrent one and may succeed when run again.
+func Retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type TrashPostgres struct {
+	db DB
+}
+
+func NewTrashPostgres(db DB) *TrashPostgres {
+	return &TrashPostgres{db: db}
+}
+
+var (
+	trashedListKeyset = keyset{key: "tl.deleted_at", cast: "::timestamptz", id: "tl.id", desc: true}
+	trashedItemKeyset = keyset{key: "ti.deleted_at", cast: "::timestamptz", id: "ti.id", desc: true}
+)
+
+// GetLists returns the user's trashed lists, most recently deleted first.
+func (r *TrashPostgres) GetLists(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedList, *models.PageInfo, error) {
+	lists := make([]*models.TrashedList, 0)
+
+	cond, order, args, err := trashedListKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.version, ul.position, tl.deleted_at FROM todo_lists tl
+	INNER JOIN users_lists ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND tl.deleted_at IS NOT NULL AND %s %s`, cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		list := &models.TrashedList{ToDoList: &models.ToDoList{}}
+		if err := rows.Scan(&list.ID, &list.Title, &list.Description, &list.Version, &list.Position, &list.DeletedAt); err != nil {
+			return nil, nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	lists, info := paginate(lists, page, func(l *models.TrashedList) *models.Cursor {
+		return &models.Cursor{Key: l.DeletedAt.Format(time.RFC3339Nano), ID: int64(l.ID)}
+	})
+
+	return lists, info, nil
+}
+
+// GetItems returns the trashed items of lists that are still alive, most
+// recently deleted first; items of a trashed list come back when the list is
+// restored.
+func (r *TrashPostgres) GetItems(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedItem, *models.PageInfo, error) {
+	items := make([]*models.TrashedItem, 0)
+
+	cond, order, args, err := trashedItemKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	// an item in several live lists is returned once, with the first of them,
+	// so that it appears on one page only
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, ti.position, ti.list_id, ti.deleted_at FROM (
+		SELECT DISTINCT ON (ti.id) ti.id, ti.title, ti.description, ti.done, ti.due, ti.labels, ti.priority, ti.assignee_id, ti.version, li.position, li.list_id, ti.deleted_at FROM todo_items ti
+		INNER JOIN lists_items li on li.item_id = ti.id INNER JOIN users_lists ul on ul.list_id = li.list_id INNER JOIN todo_lists tl on tl.id = li.list_id
+		WHERE ul.user_id = $1 AND ti.deleted_at IS NOT NULL AND tl.deleted_at IS NULL
+		ORDER BY ti.id, li.list_id
+	) ti WHERE %s %s`, cond, order)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := &models.TrashedItem{ToDoItem: &models.ToDoItem{}}
+		if err := rows.Scan(&item.ID, &item.Title, &item.Description, &item.Done, &item.Due, pq.Array(&item.Labels), &item.Priority, &item.AssigneeID, &item.Version,
+			&item.Position, &item.ListID, &item.DeletedAt); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	items, info := paginate(items, page, func(i *models.TrashedItem) *models.Cursor {
+		return &models.Cursor{Key: i.DeletedAt.Format(time.RFC3339Nano), ID: int64(i.ID)}
+	})
+
+	return items, info, nil
+}
+
+// Purge permanently removes everything that was trashed before the given time,
+// including the items of purged lists that are in no other list. Items that
+// are also in a live list, or in one trashed later, only leave the purged
+// lists, whose memberships go with them.
+func (r *TrashPostgres) Purge(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var purged int64
+	queries := []string{
+		`DELETE FROM todo_items ti USING lists_items li, todo_lists tl
+		WHERE ti.id = li.item_id AND tl.id = li.list_id AND tl.deleted_at < $1
+		AND NOT EXISTS (
+			SELECT 1 FROM lists_items o INNER JOIN todo_lists l ON l.id = o.list_id
+			WHERE o.item_id = ti.id AND (l.deleted_at IS NULL OR l.deleted_at >= $1)
+		)`,
+		"DELETE FROM todo_lists WHERE deleted_at < $1",
+		"DELETE FROM todo_items WHERE deleted_at < $1",
+	}
+
+	for _, query := range queries {
+		res, err := tx.ExecContext(ctx, query, before)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		purged += n
+	}
+
+	return purged, tx.Commit()
+}
@@ -2,25 +2,27 @@ package repository
 
 import (
 	"Todo-app/internal/models"
-	"database/sql"
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
 )
 
+// ErrEmailTaken is returned when a user signs up with an email address that
+// is already registered.
+var ErrEmailTaken = errors.New("email is already registered")
+
+const uniqueViolation = "23505"
+
 type UserRepository struct {
-	db *sql.DB
+	db DB
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (s *UserRepository) Close() {
-	err := s.db.Close()
-	if err != nil {
-		return
-	}
-}
-
-func (r *UserRepository) Create(u *models.User) (*models.User, error) {
+func (r *UserRepository) Create(ctx context.Context, u *models.User) (*models.User, error) {
 	if err := u.Validate(); err != nil {
 		return nil, err
 	}
@@ -29,27 +31,34 @@ func (r *UserRepository) Create(u *models.User) (*models.User, error) {
 		return nil, err
 	}
 
-	if err := r.db.QueryRow("INSERT INTO users (name, email, password_hash) VALUES ($1, $2, $3) RETURNING id",
+	if err := r.db.QueryRowContext(ctx, "INSERT INTO users (name, email, password_hash, time_zone) VALUES ($1, $2, $3, $4) RETURNING id",
 		u.Name,
 		u.Email,
 		u.EncryptedPassword,
+		u.TimeZone,
 	).Scan(&u.ID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, ErrEmailTaken
+		}
 		return nil, err
 	}
 
 	return nil, nil
 }
 
-func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
-	if err := r.db.QueryRow(
-		"SELECT id, name, email, password_hash FROM users WHERE email = $1",
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT id, name, email, password_hash, is_admin, time_zone FROM users WHERE email = $1",
 		email,
 	).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.EncryptedPassword,
+		&user.IsAdmin,
+		&user.TimeZone,
 	); err != nil {
 		return nil, err
 	}
@@ -57,19 +66,47 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) Find(id int) (*models.User, error) {
+func (r *UserRepository) Find(ctx context.Context, id int) (*models.User, error) {
 	user := &models.User{}
-	if err := r.db.QueryRow(
-		"SELECT id, name, email, password_hash FROM users WHERE id = $1",
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT id, name, email, password_hash, is_admin, time_zone FROM users WHERE id = $1",
 		id,
 	).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
 		&user.EncryptedPassword,
+		&user.IsAdmin,
+		&user.TimeZone,
 	); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
+
+func (r *UserRepository) SetTimeZone(ctx context.Context, id int, timeZone string) error {
+	return requireRows(r.db.ExecContext(ctx, "UPDATE users SET time_zone = $1 WHERE id = $2", timeZone, id))
+}
+
+// FindByIds returns the users among ids, in no particular order and without
+// their password hashes.
+func (r *UserRepository) FindByIds(ctx context.Context, ids []int) ([]*models.User, error) {
+	users := make([]*models.User, 0, len(ids))
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, name, email, is_admin, time_zone FROM users WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.IsAdmin, &user.TimeZone); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type WebhookPostgres struct {
+	db DB
+}
+
+func NewWebhookPostgres(db DB) *WebhookPostgres {
+	return &WebhookPostgres{db: db}
+}
+
+const webhookColumns = "id, user_id, list_id, url, events, failures, disabled_at, created_at"
+
+func (r *WebhookPostgres) Create(ctx context.Context, hook *models.Webhook) error {
+	err := r.db.QueryRowContext(ctx, "INSERT INTO webhooks (user_id, list_id, url, secret, events) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		hook.UserID, hook.ListID, hook.URL, hook.Secret, pq.Array(hook.Events),
+	).Scan(&hook.ID, &hook.CreatedAt)
+	hook.Active = err == nil
+	return err
+}
+
+var webhookKeyset = keyset{id: "id"}
+
+func (r *WebhookPostgres) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.Webhook, *models.PageInfo, error) {
+	cond, order, args, err := webhookKeyset.clause(page, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM webhooks WHERE user_id = $1 AND %s %s", webhookColumns, cond, order)
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{userId}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	hooks := make([]*models.Webhook, 0)
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	hooks, info := paginate(hooks, page, func(h *models.Webhook) *models.Cursor {
+		return &models.Cursor{ID: int64(h.ID)}
+	})
+
+	return hooks, info, nil
+}
+
+func (r *WebhookPostgres) GetById(ctx context.Context, userId, hookId int) (*models.Webhook, error) {
+	query := fmt.Sprintf("SELECT %s FROM webhooks WHERE id = $1 AND user_id = $2", webhookColumns)
+	return scanWebhook(r.db.QueryRowContext(ctx, query, hookId, userId))
+}
+
+// Update changes the fields that are set. Enabling a webhook clears its
+// failures; disabling one that is already disabled keeps the time it was
+// disabled.
+func (r *WebhookPostgres) Update(ctx context.Context, userId, hookId int, input *models.UpdateWebhookInput) error {
+	var events interface{}
+	if input.Events != nil {
+		events = pq.Array(*input.Events)
+	}
+
+	query := `UPDATE webhooks SET url = coalesce($1, url), events = coalesce($2, events),
+	failures = CASE WHEN $3::boolean THEN 0 ELSE failures END,
+	disabled_at = CASE WHEN $3::boolean THEN NULL WHEN NOT $3::boolean THEN coalesce(disabled_at, now()) ELSE disabled_at END
+	WHERE id = $4 AND user_id = $5`
+
+	return requireRows(r.db.ExecContext(ctx, query, input.URL, events, input.Active, hookId, userId))
+}
+
+func (r *WebhookPostgres) Delete(ctx context.Context, userId, hookId int) error {
+	return requireRows(r.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND user_id = $2", hookId, userId))
+}
+
+const deliveryColumns = `id, webhook_id, audit_id, event, status, attempts,
+	CASE WHEN status = 'pending' THEN next_attempt_at END, response_status, error, created_at, delivered_at`
+
+var deliveryKeyset = keyset{id: "id", desc: true}
+
+// GetDeliveries returns the deliveries of a webhook, newest first, with the
+// given status if it isn't empty.
+func (r *WebhookPostgres) GetDeliveries(ctx context.Context, hookId int, status string, page *models.PageRequest) ([]*models.WebhookDelivery, *models.PageInfo, error) {
+	cond, order, args, err := deliveryKeyset.clause(page, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM webhook_deliveries WHERE webhook_id = $1 AND ($2 = '' OR status = $2) AND %s %s", deliveryColumns, cond, order)
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{hookId, status}, args...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, info := paginate(deliveries, page, func(d *models.WebhookDelivery) *models.Cursor {
+		return &models.Cursor{ID: d.ID}
+	})
+
+	return deliveries, info, nil
+}
+
+// Redeliver queues the event of a delivery of the webhook once more, as a new
+// delivery.
+func (r *WebhookPostgres) Redeliver(ctx context.Context, hookId int, deliveryId int64) (*models.WebhookDelivery, error) {
+	query := fmt.Sprintf(`INSERT INTO webhook_deliveries (webhook_id, audit_id, event)
+	SELECT webhook_id, audit_id, event FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2
+	RETURNING %s`, deliveryColumns)
+
+	return scanDelivery(r.db.QueryRowContext(ctx, query, deliveryId, hookId))
+}
+
+// Enqueue queues the change of an audit log entry for the active webhooks
+// subscribed to event: those of its list, as long as their owner can still
+// access it, and the workspace webhooks, as long as their owner is still an
+// admin. Webhooks the entry is already queued
+// for are skipped, so enqueueing it again doesn't send it twice.
+func (r *WebhookPostgres) Enqueue(ctx context.Context, auditId int64, listId int, event string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `INSERT INTO webhook_deliveries (webhook_id, audit_id, event)
+	SELECT w.id, $1, $3 FROM webhooks w
+	WHERE w.disabled_at IS NULL AND $3 = ANY (w.events)
+	  AND (w.list_id IS NULL AND EXISTS (SELECT 1 FROM users u WHERE u.id = w.user_id AND u.is_admin) OR
+	       w.list_id = $2 AND EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = w.list_id AND ul.user_id = w.user_id))
+	  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.webhook_id = w.id AND d.audit_id = $1)`,
+		auditId, listId, event)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Claim takes up to limit due deliveries of active webhooks and counts an
+// attempt for each. A claimed delivery isn't due again until the lease ends,
+// so that it is retried if its sender stops before recording the outcome.
+// Concurrent claims skip each other's deliveries.
+func (r *WebhookPostgres) Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookJob, error) {
+	query := `UPDATE webhook_deliveries d SET attempts = d.attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+	FROM webhooks w, audit_log a
+	WHERE d.id IN (
+		SELECT dd.id FROM webhook_deliveries dd INNER JOIN webhooks ww ON ww.id = dd.webhook_id
+		WHERE dd.status = 'pending' AND dd.next_attempt_at <= now() AND ww.disabled_at IS NULL
+		ORDER BY dd.next_attempt_at LIMIT $1 FOR UPDATE OF dd SKIP LOCKED
+	) AND w.id = d.webhook_id AND a.id = d.audit_id
+	RETURNING d.id, d.webhook_id, d.audit_id, d.event, d.status, d.attempts, d.next_attempt_at, d.response_status, d.error,
+	d.created_at, d.delivered_at, w.url, w.secret,
+	a.id, a.actor_id, a.action, a.entity, a.entity_id, a.list_id, a.changes, a.created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*models.WebhookJob, 0)
+	for rows.Next() {
+		job := &models.WebhookJob{Delivery: &models.WebhookDelivery{}, Change: &models.AuditEntry{}}
+		d, e := job.Delivery, job.Change
+		var changes []byte
+
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.AuditID, &d.Event, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.ResponseStatus,
+			&d.Error, &d.CreatedAt, &d.DeliveredAt, &job.URL, &job.Secret,
+			&e.ID, &e.ActorID, &e.Action, &e.Entity, &e.EntityID, &e.ListID, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Succeed records a delivered attempt and resets the failures of its webhook.
+func (r *WebhookPostgres) Succeed(ctx context.Context, deliveryId int64, responseStatus int) error {
+	_, err := r.db.ExecContext(ctx, `WITH d AS (
+		UPDATE webhook_deliveries SET status = 'succeeded', response_status = $2, error = NULL, delivered_at = now()
+		WHERE id = $1 RETURNING webhook_id
+	) UPDATE webhooks SET failures = 0 FROM d WHERE webhooks.id = d.webhook_id`, deliveryId, responseStatus)
+	return err
+}
+
+// Fail records a failed attempt, to be retried at retryAt or, if it is nil,
+// not at all. The failure counts against the webhook, which is disabled once
+// it has failed disableAfter times in a row.
+func (r *WebhookPostgres) Fail(ctx context.Context, deliveryId int64, responseStatus *int, reason string, retryAt *time.Time, disableAfter int) error {
+	_, err := r.db.ExecContext(ctx, `WITH d AS (
+		UPDATE webhook_deliveries SET status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		response_status = $2, error = $3, next_attempt_at = coalesce($4, next_attempt_at)
+		WHERE id = $1 RETURNING webhook_id
+	) UPDATE webhooks SET failures = failures + 1,
+	disabled_at = CASE WHEN failures + 1 >= $5 THEN coalesce(disabled_at, now()) ELSE disabled_at END
+	FROM d WHERE webhooks.id = d.webhook_id`, deliveryId, responseStatus, reason, retryAt, disableAfter)
+	return err
+}
+
+// Purge removes the finished deliveries created before the given time.
+func (r *WebhookPostgres) Purge(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*models.Webhook, error) {
+	hook := &models.Webhook{}
+	if err := row.Scan(&hook.ID, &hook.UserID, &hook.ListID, &hook.URL, pq.Array(&hook.Events), &hook.Failures,
+		&hook.DisabledAt, &hook.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	hook.Active = hook.DisabledAt == nil
+	return hook, nil
+}
+
+func scanDelivery(row interface{ Scan(...interface{}) error }) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.AuditID, &d.Event, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.ResponseStatus,
+		&d.Error, &d.CreatedAt, &d.DeliveredAt); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
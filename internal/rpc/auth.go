@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// publicMethods can be called without a token.
+var publicMethods = map[string]bool{
+	todov1.AuthService_SignUp_FullMethodName:      true,
+	todov1.AuthService_CreateToken_FullMethodName: true,
+}
+
+// authenticator finds the caller by the token in the authorization metadata
+// of a call, sent as "Bearer <token>".
+type authenticator struct {
+	tokens service.Token
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, errNotAuthenticated
+	}
+
+	secret, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, errNotAuthenticated
+	}
+
+	u, err := a.tokens.Authenticate(ctx, secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errNotAuthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, ctxKeyUser, u), nil
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream is a stream whose context carries the caller.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+type authServer struct {
+	todov1.UnimplementedAuthServiceServer
+	services service.Service
+}
+
+func (s *authServer) SignUp(ctx context.Context, req *todov1.SignUpRequest) (*todov1.User, error) {
+	u := &models.User{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+		TimeZone: req.TimeZone,
+	}
+
+	if _, err := s.services.Authorization.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return userToProto(u), nil
+}
+
+func (s *authServer) CreateToken(ctx context.Context, req *todov1.CreateTokenRequest) (*todov1.CreateTokenResponse, error) {
+	u, err := s.services.Authorization.FindByEmail(ctx, req.Email)
+	if err != nil || !u.ComparePassword(req.Password) {
+		return nil, errIncorrectEmailOrPassword
+	}
+
+	token, secret, err := s.services.Token.Create(ctx, u.ID, &models.TokenInput{Name: req.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.CreateTokenResponse{Token: secret, ApiToken: tokenToProto(token)}, nil
+}
+
+func (s *authServer) ListTokens(ctx context.Context, req *todov1.ListTokensRequest) (*todov1.ListTokensResponse, error) {
+	tokens, err := s.services.Token.GetAll(ctx, userOf(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &todov1.ListTokensResponse{Tokens: make([]*todov1.ApiToken, 0, len(tokens))}
+	for _, token := range tokens {
+		res.Tokens = append(res.Tokens, tokenToProto(token))
+	}
+	return res, nil
+}
+
+func (s *authServer) RevokeToken(ctx context.Context, req *todov1.RevokeTokenRequest) (*emptypb.Empty, error) {
+	if err := s.services.Token.Revoke(ctx, userOf(ctx).ID, int(req.Id)); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *authServer) WhoAmI(ctx context.Context, req *todov1.WhoAmIRequest) (*todov1.User, error) {
+	return userToProto(userOf(ctx)), nil
+}
+
+func (s *authServer) SetTimeZone(ctx context.Context, req *todov1.SetTimeZoneRequest) (*todov1.User, error) {
+	u := userOf(ctx)
+	if err := s.services.Authorization.SetTimeZone(ctx, u.ID, req.TimeZone); err != nil {
+		return nil, err
+	}
+
+	u.TimeZone = req.TimeZone
+	return userToProto(u), nil
+}
+
+func userToProto(u *models.User) *todov1.User {
+	return &todov1.User{
+		Id:       int64(u.ID),
+		Name:     u.Name,
+		Email:    u.Email,
+		IsAdmin:  u.IsAdmin,
+		TimeZone: u.TimeZone,
+	}
+}
+
+func tokenToProto(t *models.APIToken) *todov1.ApiToken {
+	return &todov1.ApiToken{Id: int64(t.ID), Name: t.Name, CreatedAt: timestamppb.New(t.CreatedAt)}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type changeServer struct {
+	todov1.UnimplementedChangeServiceServer
+	services service.Service
+	limit    *dbLimit
+}
+
+func (s *changeServer) WatchChanges(req *todov1.WatchChangesRequest, stream todov1.ChangeService_WatchChangesServer) error {
+	ctx := stream.Context()
+
+	// the subscription lasts as long as the stream
+	sub, err := s.services.Events.Subscribe(ctx, userOf(ctx).ID, int(req.ListId), req.AfterId)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.Ready():
+		}
+
+		next, cancel := service.DBContext(ctx, s.limit.timeout)
+		changes, err := sub.Next(next)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range changes {
+			change, err := changeToProto(entry)
+			if err != nil {
+				return err
+			}
+
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func changeToProto(e *models.AuditEntry) (*todov1.Change, error) {
+	change := &todov1.Change{
+		Id:        e.ID,
+		ActorId:   int64(e.ActorID),
+		Action:    e.Action,
+		Entity:    e.Entity,
+		EntityId:  int64(e.EntityID),
+		ListId:    int64(e.ListID),
+		Changes:   make(map[string]*todov1.FieldChange, len(e.Changes)),
+		CreatedAt: timestamppb.New(e.CreatedAt),
+	}
+
+	for field, c := range e.Changes {
+		before, err := structpb.NewValue(c.Before)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := structpb.NewValue(c.After)
+		if err != nil {
+			return nil, err
+		}
+
+		change.Changes[field] = &todov1.FieldChange{Before: before, After: after}
+	}
+
+	return change, nil
+}
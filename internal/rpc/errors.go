@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+	"errors"
+	"log"
+	"sort"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo details, whose reasons are the
+// error codes of the HTTP API.
+const errorDomain = "todo-app"
+
+var (
+	errIncorrectEmailOrPassword = errors.New("incorrect email or password")
+	errNotAuthenticated         = errors.New("not authenticated")
+)
+
+var kindCodes = map[service.Kind]codes.Code{
+	service.KindInvalid:      codes.InvalidArgument,
+	service.KindNotFound:     codes.NotFound,
+	service.KindForbidden:    codes.PermissionDenied,
+	service.KindConflict:     codes.Aborted,
+	service.KindPrecondition: codes.FailedPrecondition,
+	service.KindGone:         codes.FailedPrecondition,
+	service.KindTimeout:      codes.DeadlineExceeded,
+}
+
+type errorCode struct {
+	code   codes.Code
+	reason string
+}
+
+// errorCodes are the codes of errors that aren't domain errors.
+var errorCodes = map[error]errorCode{
+	errIncorrectEmailOrPassword: {codes.Unauthenticated, "invalid_credentials"},
+	errNotAuthenticated:         {codes.Unauthenticated, "not_authenticated"},
+	models.ErrInvalidCursor:     {codes.InvalidArgument, "bad_request"},
+}
+
+// badRequestError is a malformed request, such as an invalid cursor, which
+// the HTTP API answers with 400 Bad Request.
+type badRequestError struct {
+	err error
+}
+
+func (e *badRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *badRequestError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &badRequestError{err: err}
+}
+
+func unaryErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	return res, errorStatus(ctx, info.FullMethod, err)
+}
+
+func streamErrors(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return errorStatus(stream.Context(), info.FullMethod, handler(srv, stream))
+}
+
+// errorStatus turns err into a status with the same meaning as the problem
+// the HTTP API would answer with: its code is the reason of an ErrorInfo
+// detail, and the invalid fields are listed in a BadRequest detail. Internal
+// errors are logged and not described.
+func errorStatus(ctx context.Context, method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var bad *badRequestError
+	if errors.As(err, &bad) {
+		return newStatus(codes.InvalidArgument, "bad_request", err.Error(), nil)
+	}
+
+	for target, c := range errorCodes {
+		if errors.Is(err, target) {
+			return newStatus(c.code, c.reason, err.Error(), nil)
+		}
+	}
+
+	e := service.AsError(err)
+	if e.Kind == service.KindInternal && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the driver reports a query canceled at the deadline as failed
+		e = service.AsError(ctx.Err())
+	}
+	if e.Kind == service.KindInternal {
+		log.Printf("%s: %v", method, err)
+		return newStatus(codes.Internal, e.Code, e.Message, nil)
+	}
+
+	return newStatus(kindCodes[e.Kind], e.Code, e.Message, e.Fields)
+}
+
+func newStatus(code codes.Code, reason, message string, fields map[string]string) error {
+	st := status.New(code, message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}}
+	if len(fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+		for field, description := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+		}
+		sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
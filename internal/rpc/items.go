@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type itemServer struct {
+	todov1.UnimplementedTodoItemServiceServer
+	services service.Service
+}
+
+func (s *itemServer) CreateItem(ctx context.Context, req *todov1.CreateItemRequest) (*todov1.TodoItem, error) {
+	u := userOf(ctx)
+
+	item := &models.ToDoItem{
+		Title:       req.Title,
+		Description: req.Description,
+		Due:         timePtr(req.Due),
+		Labels:      req.Labels,
+		Priority:    int(req.Priority),
+		AssigneeID:  optionalInt(req.AssigneeId),
+	}
+
+	id, err := s.services.TodoItem.Create(ctx, u.ID, int(req.ListId), item)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.item(ctx, u.ID, id)
+}
+
+func (s *itemServer) GetItem(ctx context.Context, req *todov1.GetItemRequest) (*todov1.TodoItem, error) {
+	return s.item(ctx, userOf(ctx).ID, int(req.Id))
+}
+
+func (s *itemServer) ListItems(ctx context.Context, req *todov1.ListItemsRequest) (*todov1.ListItemsResponse, error) {
+	page, err := pageRequest(req.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	items, info, err := s.services.TodoItem.GetAll(ctx, userOf(ctx).ID, int(req.ListId), page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.ListItemsResponse{Items: itemsToProto(items), Page: pageInfo(info)}, nil
+}
+
+func (s *itemServer) FindItems(ctx context.Context, req *todov1.FindItemsRequest) (*todov1.FindItemsResponse, error) {
+	page, err := pageRequest(req.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	items, info, err := s.services.TodoItem.Find(ctx, userOf(ctx).ID, req.Query, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.FindItemsResponse{Items: itemsToProto(items), Page: pageInfo(info)}, nil
+}
+
+func (s *itemServer) UpdateItem(ctx context.Context, req *todov1.UpdateItemRequest) (*todov1.TodoItem, error) {
+	u := userOf(ctx)
+
+	input := itemPatch(req.Patch)
+	input.Version = optionalInt(req.Version)
+	if err := s.services.TodoItem.Update(ctx, u.ID, int(req.Id), input); err != nil {
+		return nil, err
+	}
+
+	return s.item(ctx, u.ID, int(req.Id))
+}
+
+func (s *itemServer) DeleteItem(ctx context.Context, req *todov1.DeleteItemRequest) (*emptypb.Empty, error) {
+	if err := s.services.TodoItem.Delete(ctx, userOf(ctx).ID, int(req.Id), optionalInt(req.Version)); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *itemServer) ReorderItem(ctx context.Context, req *todov1.ReorderItemRequest) (*todov1.TodoItem, error) {
+	u := userOf(ctx)
+
+	if err := s.services.TodoItem.Reorder(ctx, u.ID, int(req.ListId), int(req.Id), positionInput(req.Position)); err != nil {
+		return nil, err
+	}
+
+	return s.item(ctx, u.ID, int(req.Id))
+}
+
+func (s *itemServer) RestoreItem(ctx context.Context, req *todov1.RestoreItemRequest) (*todov1.TodoItem, error) {
+	u := userOf(ctx)
+
+	if err := s.services.TodoItem.Restore(ctx, u.ID, int(req.Id)); err != nil {
+		return nil, err
+	}
+
+	return s.item(ctx, u.ID, int(req.Id))
+}
+
+func (s *itemServer) MoveItems(ctx context.Context, req *todov1.MoveItemsRequest) (*todov1.MoveItemsResponse, error) {
+	ids, err := s.services.TodoItem.Move(ctx, userOf(ctx).ID, transferInput(req.Selection, req.ListId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.MoveItemsResponse{Ids: int64s(ids)}, nil
+}
+
+func (s *itemServer) CopyItems(ctx context.Context, req *todov1.CopyItemsRequest) (*todov1.CopyItemsResponse, error) {
+	ids, err := s.services.TodoItem.Copy(ctx, userOf(ctx).ID, transferInput(req.Selection, req.ListId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.CopyItemsResponse{Ids: int64s(ids)}, nil
+}
+
+func (s *itemServer) UpdateItems(ctx context.Context, req *todov1.UpdateItemsRequest) (*todov1.UpdateItemsResponse, error) {
+	ids, err := s.services.TodoItem.BulkUpdate(ctx, userOf(ctx).ID, itemSelection(req.Selection), itemPatch(req.Patch))
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.UpdateItemsResponse{Ids: int64s(ids)}, nil
+}
+
+func (s *itemServer) DeleteItems(ctx context.Context, req *todov1.DeleteItemsRequest) (*todov1.DeleteItemsResponse, error) {
+	ids, err := s.services.TodoItem.BulkDelete(ctx, userOf(ctx).ID, itemSelection(req.Selection))
+	if err != nil {
+		return nil, err
+	}
+
+	return &todov1.DeleteItemsResponse{Ids: int64s(ids)}, nil
+}
+
+func (s *itemServer) item(ctx context.Context, userId, itemId int) (*todov1.TodoItem, error) {
+	item, err := s.services.TodoItem.GetById(ctx, userId, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return itemToProto(item), nil
+}
+
+func itemToProto(i *models.ToDoItem) *todov1.TodoItem {
+	res := &todov1.TodoItem{
+		Id:          int64(i.ID),
+		Title:       i.Title,
+		Description: i.Description,
+		Done:        i.Done,
+		Labels:      i.Labels,
+		Priority:    int32(i.Priority),
+		Version:     int64(i.Version),
+		Position:    i.Position,
+		ListId:      int64(i.ListID),
+	}
+
+	if i.Due != nil {
+		res.Due = timestamppb.New(*i.Due)
+	}
+	if i.AssigneeID != nil {
+		assignee := int64(*i.AssigneeID)
+		res.AssigneeId = &assignee
+	}
+
+	return res
+}
+
+func itemsToProto(items []*models.ToDoItem) []*todov1.TodoItem {
+	res := make([]*todov1.TodoItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, itemToProto(item))
+	}
+	return res
+}
+
+func itemPatch(p *todov1.ItemPatch) *models.UpdateItemInput {
+	if p == nil {
+		p = &todov1.ItemPatch{}
+	}
+
+	input := &models.UpdateItemInput{
+		Title:         p.Title,
+		Description:   p.Description,
+		Done:          p.Done,
+		Due:           timePtr(p.Due),
+		AssigneeID:    optionalInt(p.AssigneeId),
+		ClearDue:      p.ClearDue,
+		ClearAssignee: p.ClearAssignee,
+	}
+
+	if p.Priority != nil {
+		priority := int(*p.Priority)
+		input.Priority = &priority
+	}
+	if p.Labels != nil {
+		labels := p.Labels.Values
+		if labels == nil {
+			labels = []string{}
+		}
+		input.Labels = &labels
+	}
+
+	return input
+}
+
+func itemSelection(s *todov1.ItemSelection) *models.ItemSelection {
+	return &models.ItemSelection{IDs: ints(s.GetIds()), Query: s.GetQuery()}
+}
+
+func transferInput(s *todov1.ItemSelection, listId int64) *models.TransferInput {
+	return &models.TransferInput{ItemIDs: ints(s.GetIds()), Query: s.GetQuery(), ListID: int(listId)}
+}
+
+func timePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,181 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type listServer struct {
+	todov1.UnimplementedTodoListServiceServer
+	services service.Service
+}
+
+func (s *listServer) CreateList(ctx context.Context, req *todov1.CreateListRequest) (*todov1.TodoList, error) {
+	u := userOf(ctx)
+
+	id, err := s.services.TodoList.Create(ctx, u.ID, &models.ToDoList{Title: req.Title, Description: req.Description})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, u.ID, id)
+}
+
+func (s *listServer) GetList(ctx context.Context, req *todov1.GetListRequest) (*todov1.TodoList, error) {
+	return s.list(ctx, userOf(ctx).ID, int(req.Id))
+}
+
+func (s *listServer) ListLists(ctx context.Context, req *todov1.ListListsRequest) (*todov1.ListListsResponse, error) {
+	page, err := pageRequest(req.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	getAll := s.services.TodoList.GetAll
+	if req.Archived {
+		getAll = s.services.TodoList.GetArchived
+	}
+
+	lists, info, err := getAll(ctx, userOf(ctx).ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &todov1.ListListsResponse{Lists: make([]*todov1.TodoList, 0, len(lists)), Page: pageInfo(info)}
+	for _, list := range lists {
+		res.Lists = append(res.Lists, listToProto(list))
+	}
+	return res, nil
+}
+
+func (s *listServer) UpdateList(ctx context.Context, req *todov1.UpdateListRequest) (*todov1.TodoList, error) {
+	u := userOf(ctx)
+
+	patch := req.Patch
+	if patch == nil {
+		patch = &todov1.ListPatch{}
+	}
+
+	input := &models.UpdateListInput{Title: patch.Title, Description: patch.Description, Version: optionalInt(req.Version)}
+	if err := s.services.TodoList.Update(ctx, u.ID, int(req.Id), input); err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, u.ID, int(req.Id))
+}
+
+func (s *listServer) DeleteList(ctx context.Context, req *todov1.DeleteListRequest) (*emptypb.Empty, error) {
+	if err := s.services.TodoList.Delete(ctx, userOf(ctx).ID, int(req.Id), optionalInt(req.Version)); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *listServer) ReorderList(ctx context.Context, req *todov1.ReorderListRequest) (*todov1.TodoList, error) {
+	u := userOf(ctx)
+
+	if err := s.services.TodoList.Reorder(ctx, u.ID, int(req.Id), positionInput(req.Position)); err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, u.ID, int(req.Id))
+}
+
+func (s *listServer) ArchiveList(ctx context.Context, req *todov1.ArchiveListRequest) (*todov1.TodoList, error) {
+	return s.change(ctx, int(req.Id), s.services.TodoList.Archive)
+}
+
+func (s *listServer) UnarchiveList(ctx context.Context, req *todov1.UnarchiveListRequest) (*todov1.TodoList, error) {
+	return s.change(ctx, int(req.Id), s.services.TodoList.Unarchive)
+}
+
+func (s *listServer) RestoreList(ctx context.Context, req *todov1.RestoreListRequest) (*todov1.TodoList, error) {
+	return s.change(ctx, int(req.Id), s.services.TodoList.Restore)
+}
+
+func (s *listServer) DuplicateList(ctx context.Context, req *todov1.DuplicateListRequest) (*todov1.TodoList, error) {
+	list, err := s.services.TodoList.Duplicate(ctx, userOf(ctx).ID, int(req.Id), req.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	return listToProto(list), nil
+}
+
+// change applies a change without input to a list and returns the list.
+func (s *listServer) change(ctx context.Context, listId int, change func(ctx context.Context, userId, listId int) error) (*todov1.TodoList, error) {
+	u := userOf(ctx)
+
+	if err := change(ctx, u.ID, listId); err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, u.ID, listId)
+}
+
+func (s *listServer) list(ctx context.Context, userId, listId int) (*todov1.TodoList, error) {
+	list, err := s.services.TodoList.GetById(ctx, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	return listToProto(list), nil
+}
+
+func listToProto(l *models.ToDoList) *todov1.TodoList {
+	return &todov1.TodoList{
+		Id:          int64(l.ID),
+		Title:       l.Title,
+		Description: l.Description,
+		Version:     int64(l.Version),
+		Position:    l.Position,
+	}
+}
+
+// positionInput reads a position; an empty one fails the validation of the
+// service like a position with neither sibling does.
+func positionInput(p *todov1.Position) *models.PositionInput {
+	input := &models.PositionInput{}
+
+	switch sibling := p.GetSibling().(type) {
+	case *todov1.Position_Before:
+		input.Before = intPtr(sibling.Before)
+	case *todov1.Position_After:
+		input.After = intPtr(sibling.After)
+	}
+
+	return input
+}
+
+func optionalInt(v *int64) *int {
+	if v == nil {
+		return nil
+	}
+	return intPtr(*v)
+}
+
+func intPtr(v int64) *int {
+	i := int(v)
+	return &i
+}
+
+func ints(ids []int64) []int {
+	res := make([]int, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, int(id))
+	}
+	return res
+}
+
+func int64s(ids []int) []int64 {
+	res := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, int64(id))
+	}
+	return res
+}
@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+)
+
+// pageRequest reads the page a call asks for. Like the limit, after and
+// before query parameters of the HTTP API, a malformed page is a bad request.
+func pageRequest(p *todov1.PageRequest) (*models.PageRequest, error) {
+	page := &models.PageRequest{Limit: int(p.GetLimit())}
+
+	var err error
+	if page.After, err = decodeCursor(p.GetAfter()); err != nil {
+		return nil, badRequest(err)
+	}
+	if page.Before, err = decodeCursor(p.GetBefore()); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return page, badRequest(page.Validate())
+}
+
+func decodeCursor(s string) (*models.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return models.DecodeCursor(s)
+}
+
+func pageInfo(info *models.PageInfo) *todov1.PageInfo {
+	res := &todov1.PageInfo{}
+	if info.Next != nil {
+		res.Next = info.Next.Encode()
+	}
+	if info.Prev != nil {
+		res.Prev = info.Prev.Encode()
+	}
+	return res
+}
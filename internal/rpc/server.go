@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	todov1 "Todo-app/api/todo/v1"
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type ctxKey int8
+
+const ctxKeyUser ctxKey = iota
+
+// NewServer returns a gRPC server offering the services of the API described
+// in api/todo/v1. Calls are authenticated with API tokens, and their database
+// work is bounded by dbTimeout, or not at all if it is 0.
+func NewServer(services *service.Service, dbTimeout time.Duration) *grpc.Server {
+	auth := &authenticator{tokens: services.Token}
+	limit := &dbLimit{timeout: dbTimeout}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(limit.unary, unaryErrors, auth.unary),
+		grpc.ChainStreamInterceptor(streamErrors, auth.stream),
+	)
+
+	todov1.RegisterAuthServiceServer(srv, &authServer{services: *services})
+	todov1.RegisterTodoListServiceServer(srv, &listServer{services: *services})
+	todov1.RegisterTodoItemServiceServer(srv, &itemServer{services: *services})
+	todov1.RegisterChangeServiceServer(srv, &changeServer{services: *services, limit: limit})
+
+	return srv
+}
+
+// dbLimit cancels the database work of a unary call that takes longer than the
+// timeout. Streams are limited per query, see service.DBContext.
+type dbLimit struct {
+	timeout time.Duration
+}
+
+func (l *dbLimit) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, cancel := service.DBContext(ctx, l.timeout)
+	defer cancel()
+
+	return handler(ctx, req)
+}
+
+// userOf returns the caller of an authenticated call.
+func userOf(ctx context.Context) *models.User {
+	return ctx.Value(ctxKeyUser).(*models.User)
+}
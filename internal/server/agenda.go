@@ -0,0 +1,57 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type agendaView func(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error)
+
+// handleAgenda serves one of the agenda views. The time zone defaults to the
+// user's own.
+func (s *server) handleAgenda(view agendaView) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		query := r.URL.Query()
+
+		input := &models.AgendaInput{
+			TimeZone: u.TimeZone,
+			From:     query.Get("from"),
+			To:       query.Get("to"),
+		}
+
+		if tz := query.Get("tz"); tz != "" {
+			input.TimeZone = tz
+		}
+
+		for key, dst := range map[string]*int{"days": &input.Days, "limit": &input.Limit} {
+			if v := query.Get(key); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					s.error(w, r, http.StatusBadRequest, err)
+					return
+				}
+				*dst = n
+			}
+		}
+
+		if v := query.Get("include_done"); v != "" {
+			includeDone, err := strconv.ParseBool(v)
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			input.IncludeDone = includeDone
+		}
+
+		agenda, err := view(r.Context(), u.ID, input)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, agenda)
+	}
+}
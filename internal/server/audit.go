@@ -0,0 +1,116 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (s *server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		if !u.IsAdmin {
+			s.error(w, r, http.StatusForbidden, errNotAdmin)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) getListHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		vars := mux.Vars(r)
+		listId, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		entries, info, err := s.services.Audit.GetByList(r.Context(), u.ID, listId, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, entries, info)
+	}
+}
+
+func (s *server) getItemHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		entries, info, err := s.services.Audit.GetByItem(r.Context(), u.ID, itemId, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, entries, info)
+	}
+}
+
+func (s *server) handleAuditFind() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := &models.AuditFilter{}
+
+		if v := query.Get("user_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			filter.ActorID = id
+		}
+
+		for key, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+			if v := query.Get(key); v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					s.error(w, r, http.StatusBadRequest, err)
+					return
+				}
+				*dst = &t
+			}
+		}
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		entries, info, err := s.services.Audit.Find(r.Context(), filter, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, entries, info)
+	}
+}
@@ -12,6 +12,7 @@ func (s *server) handleUsersCreate() http.HandlerFunc {
 		Name     string `json:"name"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		TimeZone string `json:"time_zone"`
 	}
 
 	return func(writer http.ResponseWriter, r *http.Request) {
@@ -25,10 +26,11 @@ func (s *server) handleUsersCreate() http.HandlerFunc {
 			Name:     req.Name,
 			Email:    req.Email,
 			Password: req.Password,
+			TimeZone: req.TimeZone,
 		}
 
-		if _, err := s.services.Authorization.CreateUser(u); err != nil {
-			s.error(writer, r, http.StatusUnprocessableEntity, err)
+		if _, err := s.services.Authorization.CreateUser(r.Context(), u); err != nil {
+			s.error(writer, r, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -51,9 +53,10 @@ func (s *server) authenticateUser(next http.Handler) http.Handler {
 			return
 		}
 
-		u, err := s.services.Authorization.Find(id.(int))
+		u, err := s.services.Authorization.Find(r.Context(), id.(int))
 		if err != nil {
 			s.error(w, r, http.StatusUnauthorized, errNotAuthenticated)
+			return
 		}
 
 		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyUser, u)))
@@ -66,6 +69,25 @@ func (s *server) handleWhoAmI() http.HandlerFunc {
 	}
 }
 
+func (s *server) handleTimeZoneUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.TimeZoneInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		if err := s.services.Authorization.SetTimeZone(r.Context(), u.ID, req.TimeZone); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
 func (s *server) handleSessionsCreate() http.HandlerFunc {
 	type request struct {
 		Name     string `json:"name"`
@@ -80,7 +102,7 @@ func (s *server) handleSessionsCreate() http.HandlerFunc {
 			return
 		}
 
-		u, err := s.services.Authorization.FindByEmail(req.Email)
+		u, err := s.services.Authorization.FindByEmail(r.Context(), req.Email)
 		if err != nil || !u.ComparePassword(req.Password) {
 			s.error(w, r, http.StatusUnauthorized, errIncorrectEmailOrPassword)
 			return
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"Todo-app/internal/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// maxBatchBody is the largest batch request body, in bytes.
+const maxBatchBody = 1 << 20
+
+var (
+	// batchRequestHeaders are the headers an operation of a batch may set.
+	batchRequestHeaders = []string{"Content-Type", "If-Match", "If-None-Match"}
+	// batchResponseHeaders are the headers returned with each result.
+	batchResponseHeaders = []string{"ETag", "Link", "Location"}
+)
+
+// handleBatch runs several API requests in one transaction. The operations run
+// in order; the first one that fails rolls back all of them and ends the
+// batch, which is then answered with that operation's status.
+func (s *server) handleBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.BatchInput{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody)).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			s.error(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		results := make([]*models.BatchResult, 0, len(req.Operations))
+		var failed *models.BatchResult
+
+		err := s.services.Batch.Run(r.Context(), func(ctx context.Context, services *service.Service) error {
+			// a retried transaction runs every operation again
+			results, failed = results[:0], nil
+			batch := newServer(*services, s.sessions, s.dbTimeout)
+
+			for _, op := range req.Operations {
+				res, err := batch.runOperation(r.WithContext(ctx), op)
+				if err != nil {
+					return err
+				}
+
+				results = append(results, res)
+				if res.Status >= http.StatusBadRequest {
+					failed = res
+					return errBatchFailed
+				}
+			}
+
+			return nil
+		})
+
+		if failed != nil {
+			p := newProblem(r, failed.Status, fmt.Errorf("%w: operation %d failed", errBatchFailed, len(results)-1))
+			w.Header().Set("Content-Type", "application/problem+json")
+			s.respond(w, r, p.Status, struct {
+				*problem
+				Results []*models.BatchResult `json:"results"`
+			}{p, results})
+			return
+		}
+
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, map[string]interface{}{
+			"results": results,
+		})
+	}
+}
+
+// runOperation serves one operation of a batch as the user of the batch
+// request r. An operation that failed only because of a concurrent
+// transaction returns that error instead of its result, so that the batch is
+// run again.
+func (s *server) runOperation(r *http.Request, op *models.BatchOperation) (*models.BatchResult, error) {
+	req, err := http.NewRequestWithContext(r.Context(), op.Method, op.Path, bytes.NewReader(op.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for _, name := range batchRequestHeaders {
+		if value, ok := op.Headers[name]; ok {
+			req.Header.Set(name, value)
+		}
+	}
+	for _, cookie := range r.Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	res := &bufferedResponse{header: make(http.Header)}
+	s.ServeHTTP(res, req)
+
+	if repository.Retryable(res.err) {
+		return nil, res.err
+	}
+
+	result := &models.BatchResult{Status: res.status, Headers: make(map[string]string)}
+	if result.Status == 0 {
+		result.Status = http.StatusOK
+	}
+
+	for _, name := range batchResponseHeaders {
+		if value := res.header.Get(name); value != "" {
+			result.Headers[name] = value
+		}
+	}
+
+	if out := bytes.TrimSpace(res.body.Bytes()); len(out) > 0 {
+		if !json.Valid(out) {
+			out, _ = json.Marshal(string(out))
+		}
+		result.Body = out
+	}
+
+	return result, nil
+}
+
+// bufferedResponse keeps a response in memory, together with the error it
+// reports, if any.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	err    error
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// respondVersioned writes a list or an item with its ETag, or only the ETag
+// and 304 Not Modified when the client's copy, named in If-None-Match, is
+// still current.
+func (s *server) respondVersioned(w http.ResponseWriter, r *http.Request, version int, data interface{}) {
+	current := etag(version)
+	w.Header().Set("ETag", current)
+
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	s.respond(w, r, http.StatusOK, data)
+}
+
+// respondWritten writes a list or an item that was just changed, with its new
+// ETag.
+func (s *server) respondWritten(w http.ResponseWriter, r *http.Request, version int, data interface{}) {
+	w.Header().Set("ETag", etag(version))
+	s.respond(w, r, http.StatusOK, data)
+}
+
+// ifMatch returns the version named in the If-Match header, or nil when the
+// request has no precondition. Only a single strong ETag is accepted.
+func ifMatch(r *http.Request) (*int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return nil, nil
+	}
+
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return nil, errInvalidETag
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil {
+		return nil, errInvalidETag
+	}
+
+	return &version, nil
+}
+
+// mergePatch reads the body of a JSON merge patch request. Plain
+// application/json is accepted as well.
+func mergePatch(r *http.Request) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		return nil, errUnsupportedPatch
+	}
+
+	return io.ReadAll(r.Body)
+}
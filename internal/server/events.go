@@ -0,0 +1,128 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// eventHeartbeat is how often an idle event stream sends a comment, which
+// keeps proxies from closing it and lets clients notice a dead connection.
+const eventHeartbeat = 15 * time.Second
+
+var errStreamingUnsupported = errors.New("the connection doesn't support streaming")
+
+// handleEvents streams the changes of the user's lists as server-sent events,
+// named after the entity and action of the change, e.g. item.update. The id
+// of an event is the id of the change, so a reconnecting EventSource resumes
+// where it stopped.
+func (s *server) handleEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.error(w, r, http.StatusInternalServerError, errStreamingUnsupported)
+			return
+		}
+
+		listId := 0
+		if v := r.URL.Query().Get("list_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			listId = id
+		}
+
+		after, err := lastEventID(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		// the subscription lasts as long as the request
+		sub, err := s.services.Events.Subscribe(r.Context(), u.ID, listId, after)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(eventHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+				continue
+			case <-sub.Ready():
+			}
+
+			ctx, cancel := service.DBContext(r.Context(), s.dbTimeout)
+			changes, err := sub.Next(ctx)
+			cancel()
+			if err != nil {
+				// the status is sent already; the client reconnects and
+				// resumes after the last event it got
+				log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+				return
+			}
+
+			for _, entry := range changes {
+				if err := writeEvent(w, entry); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// lastEventID returns the id of the change a stream resumes after: the
+// Last-Event-ID header a reconnecting EventSource sends or, for clients that
+// can't set headers, the last_event_id query parameter.
+func lastEventID(r *http.Request) (*int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("last_event_id")
+	}
+	if v == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func writeEvent(w io.Writer, entry *models.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s.%s\ndata: %s\n\n", entry.ID, entry.Entity, entry.Action, data)
+	return err
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	// maxQueryDepth is how deeply the fields of a GraphQL query may nest.
+	maxQueryDepth = 8
+	// maxQueryComplexity bounds the number of fields a GraphQL query may
+	// resolve. The fields below a page count once per row of the page.
+	maxQueryComplexity = 10000
+)
+
+// graphQLRequest is the state of one GraphQL request that the resolvers
+// share: the caller and the loaders batching their lookups.
+type graphQLRequest struct {
+	r     *http.Request
+	user  *models.User
+	lists *loader[*models.ToDoList]
+	users *loader[*models.User]
+	items map[int]*loader[*models.ItemPage]
+}
+
+func (s *server) newGraphQLRequest(r *http.Request) *graphQLRequest {
+	u := r.Context().Value(ctxKeyUser).(*models.User)
+
+	return &graphQLRequest{
+		r:    r,
+		user: u,
+		lists: newLoader(func(ids []int) (map[int]*models.ToDoList, error) {
+			lists, err := s.services.TodoList.GetByIds(r.Context(), u.ID, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[int]*models.ToDoList, len(lists))
+			for _, l := range lists {
+				byId[l.ID] = l
+			}
+			return byId, nil
+		}),
+		users: newLoader(func(ids []int) (map[int]*models.User, error) {
+			users, err := s.services.Authorization.FindByIds(r.Context(), ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byId := make(map[int]*models.User, len(users))
+			for _, u := range users {
+				byId[u.ID] = u
+			}
+			return byId, nil
+		}),
+		items: make(map[int]*loader[*models.ItemPage]),
+	}
+}
+
+// itemPages returns the loader of the first pages of items of lists with the
+// given page size. Lists without items get an empty page.
+func (g *graphQLRequest) itemPages(s *server, limit int) *loader[*models.ItemPage] {
+	if l, ok := g.items[limit]; ok {
+		return l
+	}
+
+	l := newLoader(func(ids []int) (map[int]*models.ItemPage, error) {
+		pages, err := s.services.TodoItem.GetFirstPages(g.r.Context(), g.user.ID, ids, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			if _, ok := pages[id]; !ok {
+				pages[id] = &models.ItemPage{Items: []*models.ToDoItem{}, Info: &models.PageInfo{}}
+			}
+		}
+		return pages, nil
+	})
+	g.items[limit] = l
+	return l
+}
+
+// fail turns err into a GraphQL error with the code and status the HTTP API
+// would answer with. code is the status of errors that don't carry their own.
+func (g *graphQLRequest) fail(code int, err error) error {
+	return &graphQLError{newProblem(g.r, code, err)}
+}
+
+func graphQLRequestOf(p graphql.ResolveParams) *graphQLRequest {
+	return p.Context.Value(ctxKeyGraphQL).(*graphQLRequest)
+}
+
+type graphQLError struct {
+	*problem
+}
+
+func (e *graphQLError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Title
+}
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code, "status": e.Status}
+	if len(e.Errors) > 0 {
+		ext["errors"] = e.Errors
+	}
+	return ext
+}
+
+func (s *server) handleGraphQL() http.HandlerFunc {
+	type request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+
+	schema := s.graphQLSchema()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+		if err != nil {
+			s.respond(w, r, http.StatusOK, rejectedQuery(err))
+			return
+		}
+
+		if err := checkQueryCost(doc, req.OperationName, req.Variables); err != nil {
+			s.respond(w, r, http.StatusOK, rejectedQuery(err))
+			return
+		}
+
+		if validation := graphql.ValidateDocument(&schema, doc, nil); !validation.IsValid {
+			s.respond(w, r, http.StatusOK, &graphql.Result{Errors: validation.Errors})
+			return
+		}
+
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       context.WithValue(r.Context(), ctxKeyGraphQL, s.newGraphQLRequest(r)),
+		})
+
+		s.respond(w, r, http.StatusOK, result)
+	}
+}
+
+// rejectedQuery is the response to a query that isn't run at all.
+func rejectedQuery(err error) *graphql.Result {
+	formatted := gqlerrors.FormatError(err)
+	if e, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = e.Extensions()
+	}
+
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}}
+}
+
+// queryCost measures the depth and complexity of an operation, following
+// fragment spreads. Fragments are measured once and their cost reused.
+type queryCost struct {
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	measured  map[string][2]int
+	visiting  map[string]bool
+}
+
+// pagedFields are the fields returning a page of rows, whose selections are
+// resolved once per row.
+var pagedFields = map[string]bool{"lists": true, "items": true, "search": true}
+
+var (
+	errQueryTooDeep    = queryRejected("query_too_deep", fmt.Sprintf("the query nests deeper than %d fields", maxQueryDepth))
+	errQueryTooComplex = queryRejected("query_too_complex", fmt.Sprintf("the query may resolve more than %d fields", maxQueryComplexity))
+)
+
+func queryRejected(code, detail string) *graphQLError {
+	status := http.StatusUnprocessableEntity
+	return &graphQLError{&problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Code: code, Detail: detail}}
+}
+
+// checkQueryCost rejects operations nesting deeper than maxQueryDepth or
+// costing more than maxQueryComplexity, before they are run.
+func checkQueryCost(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	c := &queryCost{
+		variables: variables,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		measured:  make(map[string][2]int),
+		visiting:  make(map[string]bool),
+	}
+
+	operations := make([]*ast.OperationDefinition, 0, 1)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+
+	for _, op := range operations {
+		depth, complexity := c.selections(op.SelectionSet)
+		if depth > maxQueryDepth {
+			return errQueryTooDeep
+		}
+		if complexity > maxQueryComplexity {
+			return errQueryTooComplex
+		}
+	}
+
+	return nil
+}
+
+func (c *queryCost) selections(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, cx int
+
+		switch sel := sel.(type) {
+		case *ast.Field:
+			// introspection nests deeply but only reads the schema
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			d, cx = c.selections(sel.SelectionSet)
+			d, cx = d+1, 1+c.rows(sel)*cx
+		case *ast.InlineFragment:
+			d, cx = c.selections(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			d, cx = c.fragment(sel.Name.Value)
+		}
+
+		depth = max(depth, d)
+		// saturate, so that nested pages can't overflow
+		complexity = min(complexity+cx, maxQueryComplexity+1)
+	}
+
+	return depth, complexity
+}
+
+func (c *queryCost) fragment(name string) (int, int) {
+	if cost, ok := c.measured[name]; ok {
+		return cost[0], cost[1]
+	}
+
+	def, ok := c.fragments[name]
+	// unknown and cyclic fragments are left to the validation
+	if !ok || c.visiting[name] {
+		return 0, 0
+	}
+
+	c.visiting[name] = true
+	depth, complexity := c.selections(def.SelectionSet)
+	delete(c.visiting, name)
+
+	c.measured[name] = [2]int{depth, complexity}
+	return depth, complexity
+}
+
+// rows returns how many times the selections of a field are resolved: the
+// page size for paged fields, once for the others.
+func (c *queryCost) rows(field *ast.Field) int {
+	if !pagedFields[field.Name.Value] {
+		return 1
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return min(n, models.MaxPageSize)
+			}
+		case *ast.Variable:
+			if n, ok := c.variables[v.Name.Value].(float64); ok && n > 0 {
+				return min(int(n), models.MaxPageSize)
+			}
+		}
+		return models.MaxPageSize
+	}
+
+	return models.DefaultPageSize
+}
@@ -0,0 +1,801 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// graphQLPage is the source of the page types: the rows and the cursors of
+// the neighbouring pages, encoded as for the after and before arguments.
+type graphQLPage struct {
+	Nodes interface{}
+	Next  string
+	Prev  string
+}
+
+func newGraphQLPage(nodes interface{}, info *models.PageInfo) *graphQLPage {
+	page := &graphQLPage{Nodes: nodes}
+	if info.Next != nil {
+		page.Next = info.Next.Encode()
+	}
+	if info.Prev != nil {
+		page.Prev = info.Prev.Encode()
+	}
+	return page
+}
+
+func pageType(name string, of graphql.Type) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"nodes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(of)))},
+			"next":  &graphql.Field{Type: graphql.String, Description: "Cursor of the next page, if there is one."},
+			"prev":  &graphql.Field{Type: graphql.String, Description: "Cursor of the previous page, if there is one."},
+		},
+	})
+}
+
+var pageArgs = graphql.FieldConfigArgument{
+	"limit":  &graphql.ArgumentConfig{Type: graphql.Int},
+	"after":  &graphql.ArgumentConfig{Type: graphql.String},
+	"before": &graphql.ArgumentConfig{Type: graphql.String},
+}
+
+func withPageArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	for name, arg := range pageArgs {
+		args[name] = arg
+	}
+	return args
+}
+
+// pageRequestArgs reads the page arguments, as pageRequest does the query
+// parameters.
+func pageRequestArgs(args map[string]interface{}) (*models.PageRequest, error) {
+	page := &models.PageRequest{}
+	if limit, ok := args["limit"].(int); ok {
+		page.Limit = limit
+	}
+
+	for key, dst := range map[string]**models.Cursor{"after": &page.After, "before": &page.Before} {
+		if v, ok := args[key].(string); ok && v != "" {
+			cursor, err := models.DecodeCursor(v)
+			if err != nil {
+				return nil, err
+			}
+			*dst = cursor
+		}
+	}
+
+	return page, page.Validate()
+}
+
+// selectionArgs reads the ids or query arguments of the bulk mutations.
+func selectionArgs(args map[string]interface{}) *models.ItemSelection {
+	selection := &models.ItemSelection{}
+	if ids, ok := args["ids"].([]interface{}); ok {
+		selection.IDs = intList(ids)
+	}
+	if query, ok := args["query"].(string); ok {
+		selection.Query = query
+	}
+	return selection
+}
+
+func intList(values []interface{}) []int {
+	ints := make([]int, 0, len(values))
+	for _, v := range values {
+		ints = append(ints, v.(int))
+	}
+	return ints
+}
+
+func stringList(values []interface{}) []string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, v.(string))
+	}
+	return strs
+}
+
+func optionalInt(args map[string]interface{}, name string) *int {
+	if v, ok := args[name].(int); ok {
+		return &v
+	}
+	return nil
+}
+
+// itemPatch reads an ItemPatch input. GraphQL drops null input fields, so
+// clearDue and clearAssignee remove the due date and the assignee.
+func itemPatch(args map[string]interface{}) *models.UpdateItemInput {
+	input := &models.UpdateItemInput{}
+
+	if v, ok := args["title"].(string); ok {
+		input.Title = &v
+	}
+	if v, ok := args["description"].(string); ok {
+		input.Description = &v
+	}
+	if v, ok := args["done"].(bool); ok {
+		input.Done = &v
+	}
+	if v, ok := args["due"].(time.Time); ok {
+		input.Due = &v
+	}
+	if v, ok := args["labels"].([]interface{}); ok {
+		labels := stringList(v)
+		input.Labels = &labels
+	}
+	input.Priority = optionalInt(args, "priority")
+	input.AssigneeID = optionalInt(args, "assigneeId")
+	input.ClearDue, _ = args["clearDue"].(bool)
+	input.ClearAssignee, _ = args["clearAssignee"].(bool)
+
+	return input
+}
+
+func positionArgs(args map[string]interface{}) *models.PositionInput {
+	return &models.PositionInput{Before: optionalInt(args, "before"), After: optionalInt(args, "after")}
+}
+
+// graphQLSchema builds the schema of the GraphQL endpoint. Its queries and
+// mutations call the same services as the HTTP handlers.
+func (s *server) graphQLSchema() graphql.Schema {
+	person := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Person",
+		Description: "Another user, as far as others can see them.",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	user := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"isAdmin":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"timeZone": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	list := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ToDoList",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"position":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	item := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ToDoItem",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"done":        &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"due":         &graphql.Field{Type: graphql.DateTime},
+			"labels":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"priority":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"assigneeId":  &graphql.Field{Type: graphql.Int},
+			"version":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"position":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"listId":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"list": &graphql.Field{
+				Type: list,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphQLRequestOf(p).lists.load(p.Source.(*models.ToDoItem).ListID), nil
+				},
+			},
+			"assignee": &graphql.Field{
+				Type: person,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Source.(*models.ToDoItem).AssigneeID
+					if id == nil {
+						return nil, nil
+					}
+					return graphQLRequestOf(p).users.load(*id), nil
+				},
+			},
+		},
+	})
+
+	listPage := pageType("ToDoListPage", list)
+	itemPage := pageType("ToDoItemPage", item)
+
+	list.AddFieldConfig("items", &graphql.Field{
+		Type:        graphql.NewNonNull(itemPage),
+		Description: "The items of the list. First pages of several lists are loaded together.",
+		Args:        withPageArgs(graphql.FieldConfigArgument{}),
+		Resolve:     s.resolveListItems,
+	})
+
+	searchResult := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchResult",
+		Fields: graphql.Fields{
+			"type":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"listId":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"snippet": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"rank":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	searchPage := pageType("SearchResultPage", searchResult)
+
+	listPatch := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ListPatch",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	newItem := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "NewItem",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"due":         &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"labels":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"priority":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"assigneeId":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		},
+	})
+
+	itemPatchType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ItemPatch",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"description":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"done":          &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"due":           &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"clearDue":      &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"labels":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"priority":      &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"assigneeId":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"clearAssignee": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+	version := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Apply the change only if the resource is still at this version."}
+	ids := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int)))
+	selection := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["ids"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))}
+		args["query"] = &graphql.ArgumentConfig{Type: graphql.String}
+		return args
+	}
+	position := graphql.FieldConfigArgument{
+		"id":     id,
+		"before": &graphql.ArgumentConfig{Type: graphql.Int},
+		"after":  &graphql.ArgumentConfig{Type: graphql.Int},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"viewer": &graphql.Field{
+				Type: graphql.NewNonNull(user),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphQLRequestOf(p).user, nil
+				},
+			},
+			"lists": &graphql.Field{
+				Type: graphql.NewNonNull(listPage),
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"archived": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				}),
+				Resolve: s.resolveLists,
+			},
+			"list": &graphql.Field{
+				Type:    list,
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveList,
+			},
+			"item": &graphql.Field{
+				Type:    item,
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveItem,
+			},
+			"items": &graphql.Field{
+				Type:        graphql.NewNonNull(itemPage),
+				Description: "The items of all active lists matching a filter expression, as for GET /items.",
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+				}),
+				Resolve: s.resolveItems,
+			},
+			"search": &graphql.Field{
+				Type: graphql.NewNonNull(searchPage),
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: s.resolveSearch,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createList": &graphql.Field{
+				Type: graphql.NewNonNull(list),
+				Args: graphql.FieldConfigArgument{
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+				},
+				Resolve: s.resolveCreateList,
+			},
+			"updateList": &graphql.Field{
+				Type: graphql.NewNonNull(list),
+				Args: graphql.FieldConfigArgument{
+					"id":      id,
+					"patch":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(listPatch)},
+					"version": version,
+				},
+				Resolve: s.resolveUpdateList,
+			},
+			"deleteList": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": id, "version": version},
+				Resolve: s.resolveDeleteList,
+			},
+			"reorderList": &graphql.Field{
+				Type:    graphql.NewNonNull(list),
+				Args:    position,
+				Resolve: s.resolveListChange(s.services.TodoList.Reorder),
+			},
+			"archiveList": &graphql.Field{
+				Type:    graphql.NewNonNull(list),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveListChange(withoutInput(s.services.TodoList.Archive)),
+			},
+			"unarchiveList": &graphql.Field{
+				Type:    graphql.NewNonNull(list),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveListChange(withoutInput(s.services.TodoList.Unarchive)),
+			},
+			"restoreList": &graphql.Field{
+				Type:    graphql.NewNonNull(list),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveListChange(withoutInput(s.services.TodoList.Restore)),
+			},
+			"duplicateList": &graphql.Field{
+				Type: graphql.NewNonNull(list),
+				Args: graphql.FieldConfigArgument{
+					"id":    id,
+					"title": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+				},
+				Resolve: s.resolveDuplicateList,
+			},
+			"createItem": &graphql.Field{
+				Type: graphql.NewNonNull(item),
+				Args: graphql.FieldConfigArgument{
+					"listId": id,
+					"item":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(newItem)},
+				},
+				Resolve: s.resolveCreateItem,
+			},
+			"updateItem": &graphql.Field{
+				Type: graphql.NewNonNull(item),
+				Args: graphql.FieldConfigArgument{
+					"id":      id,
+					"patch":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(itemPatchType)},
+					"version": version,
+				},
+				Resolve: s.resolveUpdateItem,
+			},
+			"deleteItem": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": id, "version": version},
+				Resolve: s.resolveDeleteItem,
+			},
+			"reorderItem": &graphql.Field{
+				Type: graphql.NewNonNull(item),
+				Args: graphql.FieldConfigArgument{
+					"id":     id,
+					"listId": id,
+					"before": position["before"],
+					"after":  position["after"],
+				},
+				Resolve: s.resolveReorderItem,
+			},
+			"restoreItem": &graphql.Field{
+				Type:    graphql.NewNonNull(item),
+				Args:    graphql.FieldConfigArgument{"id": id},
+				Resolve: s.resolveItemChange(withoutInput(s.services.TodoItem.Restore)),
+			},
+			"moveItems": &graphql.Field{
+				Type:    ids,
+				Args:    selection(graphql.FieldConfigArgument{"listId": id}),
+				Resolve: s.resolveTransfer(false),
+			},
+			"copyItems": &graphql.Field{
+				Type:    ids,
+				Args:    selection(graphql.FieldConfigArgument{"listId": id}),
+				Resolve: s.resolveTransfer(true),
+			},
+			"updateItems": &graphql.Field{
+				Type: ids,
+				Args: selection(graphql.FieldConfigArgument{
+					"patch": &graphql.ArgumentConfig{Type: graphql.NewNonNull(itemPatchType)},
+				}),
+				Resolve: s.resolveBulkUpdate,
+			},
+			"completeItems": &graphql.Field{
+				Type:    ids,
+				Args:    selection(graphql.FieldConfigArgument{}),
+				Resolve: s.resolveBulkUpdate,
+			},
+			"deleteItems": &graphql.Field{
+				Type:    ids,
+				Args:    selection(graphql.FieldConfigArgument{}),
+				Resolve: s.resolveBulkDelete,
+			},
+			"undo": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Undoes the user's last changes and returns the number of steps undone.",
+				Args:        graphql.FieldConfigArgument{"count": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0}},
+				Resolve:     s.resolveUndo(s.services.Undo.Undo),
+			},
+			"redo": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Redoes the user's last undone changes and returns the number of steps redone.",
+				Args:        graphql.FieldConfigArgument{"count": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0}},
+				Resolve:     s.resolveUndo(s.services.Undo.Redo),
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		// the schema is static, so this is a programming error
+		panic(err)
+	}
+
+	return schema
+}
+
+func (s *server) resolveLists(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	page, err := pageRequestArgs(p.Args)
+	if err != nil {
+		return nil, g.fail(http.StatusBadRequest, err)
+	}
+
+	get := s.services.TodoList.GetAll
+	if p.Args["archived"].(bool) {
+		get = s.services.TodoList.GetArchived
+	}
+
+	lists, info, err := get(p.Context, g.user.ID, page)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return newGraphQLPage(lists, info), nil
+}
+
+func (s *server) resolveList(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	list, err := s.services.TodoList.GetById(p.Context, g.user.ID, p.Args["id"].(int))
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return list, nil
+}
+
+// resolveListItems loads the first page of items through the request's
+// loader, so that a page of lists costs one query for all of their items.
+// Later pages are read one list at a time.
+func (s *server) resolveListItems(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+	list := p.Source.(*models.ToDoList)
+
+	page, err := pageRequestArgs(p.Args)
+	if err != nil {
+		return nil, g.fail(http.StatusBadRequest, err)
+	}
+
+	if page.After == nil && page.Before == nil {
+		load := g.itemPages(s, page.Size()).load(list.ID)
+		return func() (interface{}, error) {
+			v, err := load()
+			if err != nil {
+				return nil, g.fail(http.StatusInternalServerError, err)
+			}
+
+			itemPage := v.(*models.ItemPage)
+			return newGraphQLPage(itemPage.Items, itemPage.Info), nil
+		}, nil
+	}
+
+	items, info, err := s.services.TodoItem.GetAll(p.Context, g.user.ID, list.ID, page)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return newGraphQLPage(items, info), nil
+}
+
+func (s *server) resolveItem(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	item, err := s.services.TodoItem.GetById(p.Context, g.user.ID, p.Args["id"].(int))
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return item, nil
+}
+
+func (s *server) resolveItems(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	page, err := pageRequestArgs(p.Args)
+	if err != nil {
+		return nil, g.fail(http.StatusBadRequest, err)
+	}
+
+	items, info, err := s.services.TodoItem.Find(p.Context, g.user.ID, p.Args["query"].(string), page)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return newGraphQLPage(items, info), nil
+}
+
+func (s *server) resolveSearch(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	page, err := pageRequestArgs(p.Args)
+	if err != nil {
+		return nil, g.fail(http.StatusBadRequest, err)
+	}
+
+	results, info, err := s.services.Search.Find(p.Context, g.user.ID, p.Args["query"].(string), page)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return newGraphQLPage(results, info), nil
+}
+
+func (s *server) resolveCreateList(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	list := &models.ToDoList{
+		Title:       p.Args["title"].(string),
+		Description: p.Args["description"].(string),
+	}
+
+	id, err := s.services.TodoList.Create(p.Context, g.user.ID, list)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveList(withID(p, id))
+}
+
+func (s *server) resolveUpdateList(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+	patch := p.Args["patch"].(map[string]interface{})
+
+	input := &models.UpdateListInput{Version: optionalInt(p.Args, "version")}
+	if v, ok := patch["title"].(string); ok {
+		input.Title = &v
+	}
+	if v, ok := patch["description"].(string); ok {
+		input.Description = &v
+	}
+
+	if err := s.services.TodoList.Update(p.Context, g.user.ID, p.Args["id"].(int), input); err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveList(p)
+}
+
+func (s *server) resolveDeleteList(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	if err := s.services.TodoList.Delete(p.Context, g.user.ID, p.Args["id"].(int), optionalInt(p.Args, "version")); err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return true, nil
+}
+
+// withoutInput adapts a change taking no input to resolveListChange and
+// resolveItemChange.
+func withoutInput(change func(ctx context.Context, userId, id int) error) func(ctx context.Context, userId, id int, input *models.PositionInput) error {
+	return func(ctx context.Context, userId, id int, _ *models.PositionInput) error {
+		return change(ctx, userId, id)
+	}
+}
+
+// resolveListChange applies change to the list given as id and resolves to
+// the changed list. The position arguments are passed to change if present.
+func (s *server) resolveListChange(change func(ctx context.Context, userId, listId int, input *models.PositionInput) error) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		g := graphQLRequestOf(p)
+
+		if err := change(p.Context, g.user.ID, p.Args["id"].(int), positionArgs(p.Args)); err != nil {
+			return nil, g.fail(http.StatusInternalServerError, err)
+		}
+
+		return s.resolveList(p)
+	}
+}
+
+func (s *server) resolveDuplicateList(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	list, err := s.services.TodoList.Duplicate(p.Context, g.user.ID, p.Args["id"].(int), p.Args["title"].(string))
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveList(withID(p, list.ID))
+}
+
+func (s *server) resolveCreateItem(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+	input := itemPatch(p.Args["item"].(map[string]interface{}))
+
+	item := &models.ToDoItem{
+		Title:      *input.Title,
+		Due:        input.Due,
+		Labels:     []string{},
+		AssigneeID: input.AssigneeID,
+	}
+	if input.Description != nil {
+		item.Description = *input.Description
+	}
+	if input.Labels != nil {
+		item.Labels = *input.Labels
+	}
+	if input.Priority != nil {
+		item.Priority = *input.Priority
+	}
+
+	id, err := s.services.TodoItem.Create(p.Context, g.user.ID, p.Args["listId"].(int), item)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveItem(withID(p, id))
+}
+
+func (s *server) resolveUpdateItem(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	input := itemPatch(p.Args["patch"].(map[string]interface{}))
+	input.Version = optionalInt(p.Args, "version")
+
+	if err := s.services.TodoItem.Update(p.Context, g.user.ID, p.Args["id"].(int), input); err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveItem(p)
+}
+
+func (s *server) resolveDeleteItem(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	if err := s.services.TodoItem.Delete(p.Context, g.user.ID, p.Args["id"].(int), optionalInt(p.Args, "version")); err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return true, nil
+}
+
+// resolveItemChange is resolveListChange for items.
+func (s *server) resolveItemChange(change func(ctx context.Context, userId, itemId int, input *models.PositionInput) error) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		g := graphQLRequestOf(p)
+
+		if err := change(p.Context, g.user.ID, p.Args["id"].(int), positionArgs(p.Args)); err != nil {
+			return nil, g.fail(http.StatusInternalServerError, err)
+		}
+
+		return s.resolveItem(p)
+	}
+}
+
+// resolveReorderItem moves an item within the list given by listId, as an
+// item can be in several.
+func (s *server) resolveReorderItem(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	if err := s.services.TodoItem.Reorder(p.Context, g.user.ID, p.Args["listId"].(int), p.Args["id"].(int), positionArgs(p.Args)); err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return s.resolveItem(p)
+}
+
+func (s *server) resolveTransfer(copy bool) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		g := graphQLRequestOf(p)
+
+		selection := selectionArgs(p.Args)
+		input := &models.TransferInput{ItemIDs: selection.IDs, Query: selection.Query, ListID: p.Args["listId"].(int)}
+
+		if copy {
+			ids, err := s.services.TodoItem.Copy(p.Context, g.user.ID, input)
+			if err != nil {
+				return nil, g.fail(http.StatusInternalServerError, err)
+			}
+			return ids, nil
+		}
+
+		ids, err := s.services.TodoItem.Move(p.Context, g.user.ID, input)
+		if err != nil {
+			return nil, g.fail(http.StatusInternalServerError, err)
+		}
+		return ids, nil
+	}
+}
+
+// resolveBulkUpdate applies the patch argument to the selected items, or
+// marks them done if there is none.
+func (s *server) resolveBulkUpdate(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	done := true
+	input := &models.UpdateItemInput{Done: &done}
+	if patch, ok := p.Args["patch"].(map[string]interface{}); ok {
+		input = itemPatch(patch)
+	}
+
+	ids, err := s.services.TodoItem.BulkUpdate(p.Context, g.user.ID, selectionArgs(p.Args), input)
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return ids, nil
+}
+
+func (s *server) resolveBulkDelete(p graphql.ResolveParams) (interface{}, error) {
+	g := graphQLRequestOf(p)
+
+	ids, err := s.services.TodoItem.BulkDelete(p.Context, g.user.ID, selectionArgs(p.Args))
+	if err != nil {
+		return nil, g.fail(http.StatusInternalServerError, err)
+	}
+
+	return ids, nil
+}
+
+func (s *server) resolveUndo(run func(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error)) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		g := graphQLRequestOf(p)
+
+		ops, err := run(p.Context, g.user.ID, &models.UndoInput{Count: p.Args["count"].(int)})
+		if err != nil {
+			return nil, g.fail(http.StatusInternalServerError, err)
+		}
+
+		return len(ops), nil
+	}
+}
+
+// withID returns p with its id argument replaced, to resolve a resource
+// that a mutation created.
+func withID(p graphql.ResolveParams, id int) graphql.ResolveParams {
+	p.Args = map[string]interface{}{"id": id}
+	return p
+}
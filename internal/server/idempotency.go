@@ -0,0 +1,133 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// replayedHeaders are the response headers stored with an idempotent
+// response and sent again when it is replayed.
+var replayedHeaders = []string{"Content-Type", "ETag", "Link", "Location"}
+
+// idempotent makes POST requests with an Idempotency-Key header safe to
+// retry. The first request with a key runs and its response is stored;
+// retries with the same method, path and body get that response again, with
+// an Idempotent-Replayed header. Reusing a key for another request is
+// rejected, and so is a retry while the first request is still running.
+func (s *server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		fingerprint := requestFingerprint(r, body)
+
+		record, err := s.services.Idempotency.Begin(r.Context(), u.ID, key, fingerprint)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if record != nil {
+			for name, value := range record.Headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(record.Status)
+			w.Write(record.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				ctx, cancel := s.settleContext(r)
+				defer cancel()
+
+				if err := s.services.Idempotency.Release(ctx, u.ID, key, fingerprint); err != nil {
+					log.Printf("releasing idempotency key failed: %v", err)
+				}
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		// server errors aren't stored, so that a retry can succeed
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+
+		record = &models.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Headers:     make(map[string]string),
+			Body:        rec.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			if value := w.Header().Get(name); value != "" {
+				record.Headers[name] = value
+			}
+		}
+
+		ctx, cancel := s.settleContext(r)
+		defer cancel()
+
+		if err := s.services.Idempotency.Complete(ctx, u.ID, key, record); err != nil {
+			log.Printf("storing idempotent response failed: %v", err)
+			return
+		}
+		completed = true
+	})
+}
+
+// settleContext returns the context that the outcome of a request is recorded
+// with, which doesn't end when the client goes away or the request runs out
+// of time.
+func (s *server) settleContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return service.DBContext(context.WithoutCancel(r.Context()), s.dbTimeout)
+}
+
+// requestFingerprint identifies a request by its method, path and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through and keeps a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+func (s *server) handleTemplatesCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.TemplateInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		listId, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		t, err := s.services.ListTemplate.CreateFromList(r.Context(), userID, listId, req)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, t)
+	}
+}
+
+func (s *server) handleTemplatesGetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		templates, info, err := s.services.ListTemplate.GetAll(r.Context(), userID, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, templates, info)
+	}
+}
+
+func (s *server) handleTemplatesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		t, err := s.services.ListTemplate.GetById(r.Context(), userID, id)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, t)
+	}
+}
+
+func (s *server) handleTemplatesDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.ListTemplate.Delete(r.Context(), userID, id); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleTemplatesInstantiate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.InstantiateInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		list, err := s.services.ListTemplate.Instantiate(r.Context(), userID, id, req)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, list)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+// loader batches the lookups by id of one GraphQL request. The executor
+// resolves a whole level of the query before it calls the thunks that load
+// returns, so the ids asked for on one level are fetched with one call.
+type loader[T any] struct {
+	fetch   func(ids []int) (map[int]T, error)
+	pending []int
+	loaded  map[int]T
+	err     error
+}
+
+func newLoader[T any](fetch func(ids []int) (map[int]T, error)) *loader[T] {
+	return &loader[T]{fetch: fetch, loaded: make(map[int]T)}
+}
+
+// load queues id and returns a thunk resolving to its value, or to nil if
+// there is none.
+func (l *loader[T]) load(id int) func() (interface{}, error) {
+	if _, ok := l.loaded[id]; !ok {
+		l.pending = append(l.pending, id)
+	}
+
+	return func() (interface{}, error) {
+		if len(l.pending) > 0 {
+			l.flush()
+		}
+
+		v, ok := l.loaded[id]
+		if !ok {
+			return nil, l.err
+		}
+		return v, nil
+	}
+}
+
+func (l *loader[T]) flush() {
+	ids := make([]int, 0, len(l.pending))
+	seen := make(map[int]bool, len(l.pending))
+	for _, id := range l.pending {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	l.pending = nil
+
+	values, err := l.fetch(ids)
+	if l.err = err; err != nil {
+		return
+	}
+
+	for _, id := range ids {
+		if v, ok := values[id]; ok {
+			l.loaded[id] = v
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// openAPISpec describes the routes under apiPrefix. It is written by hand;
+// checkSpec keeps it honest.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// specModels maps the schemas of the document to the types the handlers
+// encode and decode, so that their properties can be compared.
+var specModels = map[string]interface{}{
+	"ToDoList":         models.ToDoList{},
+	"ToDoItem":         models.ToDoItem{},
+	"TrashedList":      models.TrashedList{},
+	"TrashedItem":      models.TrashedItem{},
+	"ListPatch":        models.UpdateListInput{},
+	"ItemPatch":        models.UpdateItemInput{},
+	"User":             models.User{},
+	"TimeZoneInput":    models.TimeZoneInput{},
+	"PositionInput":    models.PositionInput{},
+	"TransferInput":    models.TransferInput{},
+	"ItemSelection":    models.ItemSelection{},
+	"ListTemplate":     models.ListTemplate{},
+	"TemplateItem":     models.TemplateItem{},
+	"TemplateInput":    models.TemplateInput{},
+	"InstantiateInput": models.InstantiateInput{},
+	"SmartList":        models.SmartList{},
+	"SmartListInput":   models.SmartListInput{},
+	"AuditEntry":       models.AuditEntry{},
+	"FieldChange":      models.FieldChange{},
+	"Operation":        models.Operation{},
+	"UndoInput":        models.UndoInput{},
+	"Agenda":           models.Agenda{},
+	"AgendaDay":        models.AgendaDay{},
+	"SearchResult":     models.SearchResult{},
+	"BatchOperation":   models.BatchOperation{},
+	"BatchInput":       models.BatchInput{},
+	"BatchResult":      models.BatchResult{},
+	"SyncChange":       models.SyncChange{},
+	"SyncPage":         models.SyncPage{},
+	"SyncMutation":     models.SyncMutation{},
+	"SyncInput":        models.SyncInput{},
+	"SyncConflict":     models.SyncConflict{},
+	"SyncError":        models.SyncError{},
+	"SyncResult":       models.SyncResult{},
+	"Webhook":          models.Webhook{},
+	"WebhookInput":     models.WebhookInput{},
+	"WebhookPatch":     models.UpdateWebhookInput{},
+	"WebhookDelivery":  models.WebhookDelivery{},
+	"Problem":          problem{},
+}
+
+type specDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type specSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       string                 `json:"type"`
+	Properties map[string]*specSchema `json:"properties"`
+}
+
+var specMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+func (s *server) handleOpenAPI() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(openAPISpec)
+	}
+}
+
+// checkSpec compares the document with the router and the models: every
+// versioned route has to be described and every described operation has to
+// exist, and the schemas have to have the properties the models encode to.
+// It returns all differences at once.
+func (s *server) checkSpec() error {
+	doc := &specDocument{}
+	if err := json.Unmarshal(openAPISpec, doc); err != nil {
+		return fmt.Errorf("openapi.json: %w", err)
+	}
+
+	problems := make([]string, 0)
+
+	routes, err := versionedRoutes(s.router)
+	if err != nil {
+		return err
+	}
+
+	described := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			if specMethods[method] {
+				described[strings.ToUpper(method)+" "+pathShape(path)] = true
+			}
+		}
+	}
+
+	for op := range routes {
+		if !described[op] {
+			problems = append(problems, fmt.Sprintf("route %s is not described", op))
+		}
+	}
+	for op := range described {
+		if !routes[op] {
+			problems = append(problems, fmt.Sprintf("operation %s has no route", op))
+		}
+	}
+
+	for name, model := range specModels {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("schema %s is missing", name))
+			continue
+		}
+		problems = append(problems, compareSchema(name, schema, reflect.TypeOf(model))...)
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("openapi.json doesn't match the API:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+
+	return nil
+}
+
+// versionedRoutes returns the method and path shape of every route under
+// apiPrefix, relative to it.
+func versionedRoutes(router *mux.Router) (map[string]bool, error) {
+	routes := make(map[string]bool)
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(tpl, apiPrefix+"/") {
+			return nil
+		}
+
+		// subrouters have no methods of their own
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			routes[method+" "+pathShape(strings.TrimPrefix(tpl, apiPrefix))] = true
+		}
+		return nil
+	})
+
+	return routes, err
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// pathShape drops the names of path parameters, which differ between the
+// router and the document.
+func pathShape(path string) string {
+	return pathParam.ReplaceAllString(path, "{}")
+}
+
+func compareSchema(name string, schema *specSchema, t reflect.Type) []string {
+	problems := make([]string, 0)
+	fields := jsonFields(t)
+
+	for field, fieldType := range fields {
+		property, ok := schema.Properties[field]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("schema %s lacks property %s", name, field))
+			continue
+		}
+
+		want := jsonType(fieldType)
+		if property.Ref == "" && want != "" && property.Type != want {
+			problems = append(problems, fmt.Sprintf("property %s.%s is %q, the model encodes %q", name, field, property.Type, want))
+		}
+	}
+
+	for field := range schema.Properties {
+		if _, ok := fields[field]; !ok {
+			problems = append(problems, fmt.Sprintf("schema %s has property %s, which the model lacks", name, field))
+		}
+	}
+
+	return problems
+}
+
+// jsonFields returns the names encoding/json gives the fields of a struct,
+// with the fields of embedded structs promoted.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+
+		switch {
+		case name == "-":
+			continue
+		case f.Anonymous && name == "":
+			for embedded, ft := range jsonFields(f.Type) {
+				fields[embedded] = ft
+			}
+			continue
+		case !f.IsExported():
+			continue
+		case name == "":
+			name = f.Name
+		}
+
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// jsonType returns the JSON Schema type of values of t, or "" for values of
+// any type.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return "string"
+	case rawMessageType:
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return ""
+}
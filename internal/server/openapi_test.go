@@ -0,0 +1,25 @@
+package server
+
+import (
+	"Todo-app/internal/service"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+)
+
+func TestSpecMatchesRoutes(t *testing.T) {
+	// the handlers are only registered, never called, so the services can
+	// be empty
+	services := service.Service{
+		Agenda:   &service.AgendaService{},
+		Undo:     &service.UndoService{},
+		TodoList: &service.TodoListService{},
+		TodoItem: &service.TodoItemService{},
+	}
+	s := newServer(services, sessions.NewCookieStore([]byte("test")), time.Second)
+
+	if err := s.checkSpec(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type pageResponse struct {
+	Data interface{} `json:"data"`
+	Next string      `json:"next,omitempty"`
+	Prev string      `json:"prev,omitempty"`
+}
+
+// pageRequest reads the limit, after and before query parameters.
+func pageRequest(r *http.Request) (*models.PageRequest, error) {
+	query := r.URL.Query()
+	page := &models.PageRequest{}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		page.Limit = limit
+	}
+
+	for key, dst := range map[string]**models.Cursor{"after": &page.After, "before": &page.Before} {
+		if v := query.Get(key); v != "" {
+			cursor, err := models.DecodeCursor(v)
+			if err != nil {
+				return nil, err
+			}
+			*dst = cursor
+		}
+	}
+
+	return page, page.Validate()
+}
+
+// respondPage writes one page of a collection together with the links to the
+// neighbouring pages, both in the body and in a Link header.
+func (s *server) respondPage(w http.ResponseWriter, r *http.Request, data interface{}, info *models.PageInfo) {
+	s.respond(w, r, http.StatusOK, newPage(w, r, data, info))
+}
+
+// newPage builds the body of a page response and sets its Link header.
+func newPage(w http.ResponseWriter, r *http.Request, data interface{}, info *models.PageInfo) *pageResponse {
+	res := &pageResponse{
+		Data: data,
+		Next: pageLink(r, "after", info.Next),
+		Prev: pageLink(r, "before", info.Prev),
+	}
+
+	links := make([]string, 0)
+	if res.Next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, res.Next))
+	}
+	if res.Prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, res.Prev))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	return res
+}
+
+func pageLink(r *http.Request, key string, cursor *models.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	query := r.URL.Query()
+	query.Del("after")
+	query.Del("before")
+	query.Set(key, cursor.Encode())
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
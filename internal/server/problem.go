@@ -0,0 +1,88 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/service"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// problem is an RFC 7807 problem details object. Code is stable and meant for
+// clients to switch on; Errors names the invalid fields of the input.
+type problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Code     string            `json:"code"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+var kindStatus = map[service.Kind]int{
+	service.KindInvalid:      http.StatusUnprocessableEntity,
+	service.KindNotFound:     http.StatusNotFound,
+	service.KindForbidden:    http.StatusForbidden,
+	service.KindConflict:     http.StatusConflict,
+	service.KindPrecondition: http.StatusPreconditionFailed,
+	service.KindGone:         http.StatusGone,
+	service.KindTimeout:      http.StatusServiceUnavailable,
+}
+
+// statusCodes are the codes of errors that aren't domain errors, such as a
+// malformed request.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:           "bad_request",
+	http.StatusUnauthorized:         "unauthorized",
+	http.StatusForbidden:            "forbidden",
+	http.StatusNotFound:             "not_found",
+	http.StatusConflict:             "conflict",
+	http.StatusPreconditionFailed:   "precondition_failed",
+	http.StatusUnsupportedMediaType: "unsupported_media_type",
+	http.StatusUnprocessableEntity:  "validation_failed",
+}
+
+var errorCodes = map[error]string{
+	errIncorrectEmailOrPassword: "invalid_credentials",
+	errNotAuthenticated:         "not_authenticated",
+	errNotAdmin:                 "admin_required",
+	errInvalidETag:              "invalid_etag",
+	errUnsupportedPatch:         "unsupported_patch",
+	errBatchFailed:              "batch_failed",
+}
+
+// newProblem describes err. Domain errors bring their own status; for other
+// errors code is used. Internal errors are logged and not described, so
+// nothing about the storage leaks to clients.
+func newProblem(r *http.Request, code int, err error) *problem {
+	if errors.Is(err, models.ErrInvalidCursor) {
+		// a cursor of another collection is only caught by the query
+		code = http.StatusBadRequest
+	}
+	p := &problem{Type: "about:blank", Status: code, Instance: r.URL.Path}
+
+	e := service.AsError(err)
+	if e.Kind == service.KindInternal && errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		// the driver reports a query canceled at the deadline as failed
+		e = service.AsError(r.Context().Err())
+	}
+
+	if e.Kind != service.KindInternal {
+		p.Status, p.Code, p.Detail, p.Errors = kindStatus[e.Kind], e.Code, e.Message, e.Fields
+	} else if code >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		p.Code = e.Code
+	} else {
+		p.Code, p.Detail = statusCodes[code], err.Error()
+		for target, c := range errorCodes {
+			if errors.Is(err, target) {
+				p.Code = c
+			}
+		}
+	}
+
+	p.Title = http.StatusText(p.Status)
+	return p
+}
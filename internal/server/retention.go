@@ -0,0 +1,34 @@
+package server
+
+import (
+	"Todo-app/internal/service"
+	"context"
+	"log"
+	"time"
+)
+
+// runPeriodically calls fn every interval, each time with a context bounded
+// by dbTimeout, and logs its failures. fn returns how much it did: a purge
+// logs how many rows it removed, while a job that drains a queue is called
+// again right away as long as it finds work, and only waits for the next
+// tick once the queue is empty or fn failed.
+func runPeriodically(name string, interval, dbTimeout time.Duration, fn func(ctx context.Context) (int64, error), drain bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := service.DBContext(context.Background(), dbTimeout)
+		n, err := fn(ctx)
+		cancel()
+		switch {
+		case err != nil:
+			log.Printf("%s failed: %v", name, err)
+		case n > 0 && drain:
+			continue
+		case n > 0:
+			log.Printf("%s removed %d rows", name, n)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"net/http"
+)
+
+func (s *server) handleSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		results, info, err := s.services.Search.Find(r.Context(), userID, r.URL.Query().Get("q"), page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, results, info)
+	}
+}
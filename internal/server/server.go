@@ -7,22 +7,36 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	"net/http"
+	"time"
 )
 
 var (
 	errIncorrectEmailOrPassword = errors.New("incorrect email or password")
 	errNotAuthenticated         = errors.New("not authenticated")
+	errNotAdmin                 = errors.New("administrator access required")
+	errInvalidETag              = errors.New("If-Match must be a single entity tag")
+	errUnsupportedPatch         = errors.New("patch must be sent as application/merge-patch+json")
+	errBatchFailed              = errors.New("batch rolled back")
 )
 
 const (
 	sessionName        = "tempSessionName"
 	ctxKeyUser  ctxKey = iota
+	ctxKeyGraphQL
 )
 
+// apiPrefix is the root of the current version of the API.
+const apiPrefix = "/api/v1"
+
+// streamRoute names the routes that stream responses for as long as the
+// client stays connected.
+const streamRoute = "stream"
+
 type server struct {
-	router   *mux.Router
-	services service.Service
-	sessions sessions.Store
+	router    *mux.Router
+	services  service.Service
+	sessions  sessions.Store
+	dbTimeout time.Duration
 }
 
 type ctxKey int8
@@ -31,11 +45,14 @@ func (s *server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	s.router.ServeHTTP(writer, request)
 }
 
-func newServer(services service.Service, sessionStore sessions.Store) *server {
+// newServer bounds the database work of a request by dbTimeout, or not at
+// all if it is 0.
+func newServer(services service.Service, sessionStore sessions.Store, dbTimeout time.Duration) *server {
 	s := &server{
-		router:   mux.NewRouter(),
-		services: services,
-		sessions: sessionStore,
+		router:    mux.NewRouter(),
+		services:  services,
+		sessions:  sessionStore,
+		dbTimeout: dbTimeout,
 	}
 
 	s.configureRouter()
@@ -44,30 +61,144 @@ func newServer(services service.Service, sessionStore sessions.Store) *server {
 }
 
 func (s *server) configureRouter() {
-	s.router.HandleFunc("/users", s.handleUsersCreate()).Methods("POST")
-	s.router.HandleFunc("/sessions", s.handleSessionsCreate()).Methods("POST")
+	s.router.Use(s.limitDB)
+
+	v1 := s.router.PathPrefix(apiPrefix).Subrouter()
+	v1.HandleFunc("/openapi.json", s.handleOpenAPI()).Methods("GET")
+	s.routes(v1, v1.NewRoute().Subrouter())
+
+	// the unversioned routes predate /api/v1 and are kept for existing clients
+	legacy := s.router.NewRoute().Subrouter()
+	legacy.Use(deprecated)
+	s.routes(legacy, legacy.PathPrefix("/private").Subrouter())
+}
+
+// routes registers the API on public, with the routes that need a session on
+// private.
+func (s *server) routes(public, private *mux.Router) {
+	public.HandleFunc("/users", s.handleUsersCreate()).Methods("POST")
+	public.HandleFunc("/sessions", s.handleSessionsCreate()).Methods("POST")
 
-	private := s.router.PathPrefix("/private").Subrouter()
 	private.Use(s.authenticateUser)
+	private.Use(s.idempotent)
 	private.HandleFunc("/whoami", s.handleWhoAmI()).Methods("GET")
+	private.HandleFunc("/whoami/time-zone", s.handleTimeZoneUpdate()).Methods("PUT")
+	private.HandleFunc("/trash/lists", s.handleTrashLists()).Methods("GET")
+	private.HandleFunc("/trash/items", s.handleTrashItems()).Methods("GET")
+	private.HandleFunc("/search", s.handleSearch()).Methods("GET")
+	private.HandleFunc("/events", s.handleEvents()).Methods("GET").Name(streamRoute)
+	private.HandleFunc("/sync", s.handleSync()).Methods("GET")
+	private.HandleFunc("/sync", s.handleSyncPush()).Methods("POST")
+	private.HandleFunc("/undo", s.handleUndo()).Methods("POST")
+	private.HandleFunc("/batch", s.handleBatch()).Methods("POST")
+	private.HandleFunc("/graphql", s.handleGraphQL()).Methods("POST")
+	private.HandleFunc("/redo", s.handleRedo()).Methods("POST")
 
 	todos := private.PathPrefix("/todos").Subrouter()
 	todos.HandleFunc("/", s.handleTodosCreate()).Methods("POST")
+	todos.HandleFunc("/archived", s.getArchivedLists()).Methods("GET")
 	todos.HandleFunc("/{id}", s.handleTodosUpdate()).Methods("PUT")
+	todos.HandleFunc("/{id}", s.handleTodosPatch()).Methods("PATCH")
 	todos.HandleFunc("/{id}", s.handleTodosDelete()).Methods("DELETE")
 	todos.HandleFunc("/{id}", s.getListById()).Methods("GET")
+	todos.HandleFunc("/{id}/position", s.handleTodosReorder()).Methods("PUT")
+	todos.HandleFunc("/{id}/duplicate", s.handleTodosDuplicate()).Methods("POST")
+	todos.HandleFunc("/{id}/template", s.handleTemplatesCreate()).Methods("POST")
+	todos.HandleFunc("/{id}/archive", s.handleTodosArchive()).Methods("POST")
+	todos.HandleFunc("/{id}/archive", s.handleTodosUnarchive()).Methods("DELETE")
+	todos.HandleFunc("/{id}/restore", s.handleTodosRestore()).Methods("POST")
+	todos.HandleFunc("/{id}/history", s.getListHistory()).Methods("GET")
 	todos.HandleFunc("/", s.getAllLists()).Methods("GET")
 
 	items := todos.PathPrefix("/{id}/items").Subrouter()
 	items.HandleFunc("/", s.getAllItems()).Methods("GET")
-	items.HandleFunc("/{id}", s.getItemById()).Methods("GET")
+	items.HandleFunc("/{itemId}", s.getItemById()).Methods("GET")
 	items.HandleFunc("/", s.createItem()).Methods("POST")
-	items.HandleFunc("/{id}", s.updateItem()).Methods("PUT")
-	items.HandleFunc("/{id}", s.deleteItem()).Methods("DELETE")
+	items.HandleFunc("/{itemId}", s.updateItem()).Methods("PUT")
+	items.HandleFunc("/{itemId}", s.patchItem()).Methods("PATCH")
+	items.HandleFunc("/{itemId}", s.deleteItem()).Methods("DELETE")
+	items.HandleFunc("/{itemId}/position", s.reorderItem()).Methods("PUT")
+	items.HandleFunc("/{itemId}/move", s.moveItem()).Methods("POST")
+	items.HandleFunc("/{itemId}/copy", s.copyItem()).Methods("POST")
+	items.HandleFunc("/{itemId}/restore", s.restoreItem()).Methods("POST")
+	items.HandleFunc("/{itemId}/history", s.getItemHistory()).Methods("GET")
+
+	bulkItems := private.PathPrefix("/items").Subrouter()
+	bulkItems.HandleFunc("/", s.findItems()).Methods("GET")
+	bulkItems.HandleFunc("/move", s.moveItems()).Methods("POST")
+	bulkItems.HandleFunc("/copy", s.copyItems()).Methods("POST")
+	bulkItems.HandleFunc("/update", s.bulkUpdateItems()).Methods("POST")
+	bulkItems.HandleFunc("/complete", s.bulkCompleteItems()).Methods("POST")
+	bulkItems.HandleFunc("/delete", s.bulkDeleteItems()).Methods("POST")
+
+	templates := private.PathPrefix("/templates").Subrouter()
+	templates.HandleFunc("/", s.handleTemplatesGetAll()).Methods("GET")
+	templates.HandleFunc("/{id}", s.handleTemplatesGet()).Methods("GET")
+	templates.HandleFunc("/{id}", s.handleTemplatesDelete()).Methods("DELETE")
+	templates.HandleFunc("/{id}/lists", s.handleTemplatesInstantiate()).Methods("POST")
+
+	agenda := private.PathPrefix("/agenda").Subrouter()
+	agenda.HandleFunc("/", s.handleAgenda(s.services.Agenda.Range)).Methods("GET")
+	agenda.HandleFunc("/today", s.handleAgenda(s.services.Agenda.Today)).Methods("GET")
+	agenda.HandleFunc("/upcoming", s.handleAgenda(s.services.Agenda.Upcoming)).Methods("GET")
+	agenda.HandleFunc("/overdue", s.handleAgenda(s.services.Agenda.Overdue)).Methods("GET")
+
+	smartLists := private.PathPrefix("/smart-lists").Subrouter()
+	smartLists.HandleFunc("/", s.handleSmartListsCreate()).Methods("POST")
+	smartLists.HandleFunc("/", s.handleSmartListsGetAll()).Methods("GET")
+	smartLists.HandleFunc("/{id}", s.handleSmartListsGet()).Methods("GET")
+	smartLists.HandleFunc("/{id}", s.handleSmartListsUpdate()).Methods("PUT")
+	smartLists.HandleFunc("/{id}", s.handleSmartListsDelete()).Methods("DELETE")
+	smartLists.HandleFunc("/{id}/items", s.handleSmartListsItems()).Methods("GET")
+
+	webhooks := private.PathPrefix("/webhooks").Subrouter()
+	webhooks.HandleFunc("/", s.handleWebhooksCreate()).Methods("POST")
+	webhooks.HandleFunc("/", s.handleWebhooksGetAll()).Methods("GET")
+	webhooks.HandleFunc("/{id}", s.handleWebhooksGet()).Methods("GET")
+	webhooks.HandleFunc("/{id}", s.handleWebhooksPatch()).Methods("PATCH")
+	webhooks.HandleFunc("/{id}", s.handleWebhooksDelete()).Methods("DELETE")
+	webhooks.HandleFunc("/{id}/deliveries", s.handleWebhookDeliveries()).Methods("GET")
+	webhooks.HandleFunc("/{id}/deliveries/{deliveryId}/redeliver", s.handleWebhookRedeliver()).Methods("POST")
+
+	admin := private.PathPrefix("/admin").Subrouter()
+	admin.Use(s.requireAdmin)
+	admin.HandleFunc("/audit", s.handleAuditFind()).Methods("GET")
+}
+
+// limitDB cancels the database work of a request that takes longer than the
+// timeout. Streams are limited per query, see service.DBContext.
+func (s *server) limitDB(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil && route.GetName() == streamRoute {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := service.DBContext(r.Context(), s.dbTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// deprecated marks the responses of the unversioned routes.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		next.ServeHTTP(w, r)
+	})
 }
 
+// error writes err as application/problem+json. code is the status of errors
+// that don't carry their own; see newProblem.
 func (s *server) error(w http.ResponseWriter, r *http.Request, code int, err error) {
-	s.respond(w, r, code, map[string]string{"error": err.Error()})
+	if b, ok := w.(*bufferedResponse); ok {
+		b.err = err
+	}
+
+	p := newProblem(r, code, err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	s.respond(w, r, p.Status, p)
 }
 
 func (s *server) respond(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+func (s *server) handleSmartListsCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.SmartListInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		list, err := s.services.SmartList.Create(r.Context(), userID, req)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, list)
+	}
+}
+
+func (s *server) handleSmartListsGetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		lists, info, err := s.services.SmartList.GetAll(r.Context(), userID, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, lists, info)
+	}
+}
+
+func (s *server) handleSmartListsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		list, err := s.services.SmartList.GetById(r.Context(), userID, id)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, list)
+	}
+}
+
+func (s *server) handleSmartListsUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.SmartListInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.SmartList.Update(r.Context(), userID, id, req); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleSmartListsDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.SmartList.Delete(r.Context(), userID, id); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleSmartListsItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		items, info, err := s.services.SmartList.GetItems(r.Context(), userID, id, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, items, info)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// handleSync returns a page of the changes since the token query parameter,
+// or of everything the user can see without one.
+func (s *server) handleSync() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		limit := 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+			limit = n
+		}
+
+		page, err := s.services.Sync.Changes(r.Context(), userID, r.URL.Query().Get("token"), limit)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, page)
+	}
+}
+
+// handleSyncPush applies the mutations a client made offline and answers with
+// the outcome of each.
+func (s *server) handleSyncPush() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.SyncInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		results, err := s.services.Sync.Push(r.Context(), userID, req)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, map[string]interface{}{
+			"results": results,
+		})
+	}
+}
@@ -2,16 +2,23 @@ package server
 
 import (
 	"Todo-app/internal/models"
+	"Todo-app/internal/service"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func (s *server) createItem() http.HandlerFunc {
 	type request struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Due         *time.Time `json:"due"`
+		Labels      []string   `json:"labels"`
+		Priority    int        `json:"priority"`
+		AssigneeID  *int       `json:"assignee_id"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -32,12 +39,16 @@ func (s *server) createItem() http.HandlerFunc {
 			Title:       req.Title,
 			Description: req.Description,
 			Done:        false,
+			Due:         req.Due,
+			Labels:      req.Labels,
+			Priority:    req.Priority,
+			AssigneeID:  req.AssigneeID,
 		}
 
 		u := r.Context().Value(ctxKeyUser).(*models.User)
 		userId := u.ID
 
-		id, err := s.services.TodoItem.Create(userId, listId, item)
+		id, err := s.services.TodoItem.Create(r.Context(), userId, listId, item)
 		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
@@ -61,13 +72,19 @@ func (s *server) getAllItems() http.HandlerFunc {
 			return
 		}
 
-		items, err := s.services.TodoItem.GetAll(userId, listId)
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		items, info, err := s.services.TodoItem.GetAll(r.Context(), userId, listId, page)
 		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		s.respond(w, r, http.StatusOK, items)
+		s.respondPage(w, r, items, info)
 	}
 }
 
@@ -77,27 +94,31 @@ func (s *server) getItemById() http.HandlerFunc {
 		userId := u.ID
 
 		vars := mux.Vars(r)
-		itemId, err := strconv.Atoi(vars["id"])
+		itemId, err := strconv.Atoi(vars["itemId"])
 		if err != nil {
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		item, err := s.services.TodoItem.GetById(userId, itemId)
+		item, err := s.services.TodoItem.GetById(r.Context(), userId, itemId)
 		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		s.respond(w, r, http.StatusOK, item)
+		s.respondVersioned(w, r, item.Version, item)
 	}
 }
 
 func (s *server) updateItem() http.HandlerFunc {
 	type request struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Done        bool   `json:"done"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Done        bool       `json:"done"`
+		Due         *time.Time `json:"due"`
+		Labels      []string   `json:"labels"`
+		Priority    int        `json:"priority"`
+		AssigneeID  *int       `json:"assignee_id"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -111,7 +132,7 @@ func (s *server) updateItem() http.HandlerFunc {
 		userId := u.ID
 
 		vars := mux.Vars(r)
-		itemId, err := strconv.Atoi(vars["id"])
+		itemId, err := strconv.Atoi(vars["itemId"])
 		if err != nil {
 			s.error(w, r, http.StatusBadRequest, err)
 			return
@@ -121,9 +142,121 @@ func (s *server) updateItem() http.HandlerFunc {
 			Title:       &req.Title,
 			Description: &req.Description,
 			Done:        &req.Done,
+			Due:         req.Due,
+			Labels:      &req.Labels,
+			Priority:    &req.Priority,
+			AssigneeID:  req.AssigneeID,
+		}
+		input.ClearDue, input.ClearAssignee = req.Due == nil, req.AssigneeID == nil
+
+		input.Version, err = ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.writeItem(w, r, userId, itemId, input)
+	}
+}
+
+// patchItem applies a JSON merge patch (RFC 7396) to an item: only the
+// fields in the patch change, and null removes a field.
+func (s *server) patchItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := mergePatch(r)
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, errUnsupportedPatch) {
+				code = http.StatusUnsupportedMediaType
+			}
+			s.error(w, r, code, err)
+			return
+		}
+
+		input, err := models.ParseItemMergePatch(body)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
 		}
 
-		if err := s.services.TodoItem.Update(userId, itemId, input); err != nil {
+		input.Version, err = ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if input.Empty() {
+			s.keepItem(w, r, userId, itemId, input.Version)
+			return
+		}
+
+		s.writeItem(w, r, userId, itemId, input)
+	}
+}
+
+// writeItem updates an item and responds with its new state.
+func (s *server) writeItem(w http.ResponseWriter, r *http.Request, userId, itemId int, input *models.UpdateItemInput) {
+	if err := s.services.TodoItem.Update(r.Context(), userId, itemId, input); err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	item, err := s.services.TodoItem.GetById(r.Context(), userId, itemId)
+	if err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respondWritten(w, r, item.Version, item)
+}
+
+// keepItem answers an empty merge patch: nothing is written and the item is
+// returned as it is, unless it has changed since the If-Match version.
+func (s *server) keepItem(w http.ResponseWriter, r *http.Request, userId, itemId int, version *int) {
+	item, err := s.services.TodoItem.GetById(r.Context(), userId, itemId)
+	if err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if version != nil && *version != item.Version {
+		s.error(w, r, http.StatusPreconditionFailed, service.ErrVersionMismatch)
+		return
+	}
+
+	s.respondWritten(w, r, item.Version, item)
+}
+
+func (s *server) deleteItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		version, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		err = s.services.TodoItem.Delete(r.Context(), userId, itemId, version)
+		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
@@ -132,20 +265,257 @@ func (s *server) updateItem() http.HandlerFunc {
 	}
 }
 
-func (s *server) deleteItem() http.HandlerFunc {
+func (s *server) reorderItem() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.PositionInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
 		u := r.Context().Value(ctxKeyUser).(*models.User)
 		userId := u.ID
 
 		vars := mux.Vars(r)
-		itemId, err := strconv.Atoi(vars["id"])
+		listId, err := strconv.Atoi(vars["id"])
 		if err != nil {
 			s.error(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		err = s.services.TodoItem.Delete(userId, itemId)
+		itemId, err := strconv.Atoi(vars["itemId"])
 		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.services.TodoItem.Reorder(r.Context(), userId, listId, itemId, req); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) moveItem() http.HandlerFunc {
+	type request struct {
+		ListID int `json:"list_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		input := &models.TransferInput{ItemIDs: []int{itemId}, ListID: req.ListID}
+		if _, err := s.services.TodoItem.Move(r.Context(), userId, input); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) copyItem() http.HandlerFunc {
+	type request struct {
+		ListID int `json:"list_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		input := &models.TransferInput{ItemIDs: []int{itemId}, ListID: req.ListID}
+		ids, err := s.services.TodoItem.Copy(r.Context(), userId, input)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, map[string]interface{}{
+			"id": ids[0],
+		})
+	}
+}
+
+func (s *server) findItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		items, info, err := s.services.TodoItem.Find(r.Context(), u.ID, r.URL.Query().Get("q"), page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, items, info)
+	}
+}
+
+func (s *server) moveItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		input := &models.TransferInput{}
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		ids, err := s.services.TodoItem.Move(r.Context(), u.ID, input)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, map[string]interface{}{
+			"ids": ids,
+		})
+	}
+}
+
+// bulkUpdateItems applies a JSON merge patch, as for a single item, to every
+// selected item.
+func (s *server) bulkUpdateItems() http.HandlerFunc {
+	type request struct {
+		models.ItemSelection
+		Set json.RawMessage `json:"set"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		input, err := models.ParseItemMergePatch(req.Set)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.bulkUpdate(w, r, &req.ItemSelection, input)
+	}
+}
+
+func (s *server) bulkCompleteItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		selection := &models.ItemSelection{}
+		if err := json.NewDecoder(r.Body).Decode(selection); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		done := true
+		s.bulkUpdate(w, r, selection, &models.UpdateItemInput{Done: &done})
+	}
+}
+
+func (s *server) bulkUpdate(w http.ResponseWriter, r *http.Request, selection *models.ItemSelection, input *models.UpdateItemInput) {
+	u := r.Context().Value(ctxKeyUser).(*models.User)
+
+	ids, err := s.services.TodoItem.BulkUpdate(r.Context(), u.ID, selection, input)
+	if err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respond(w, r, http.StatusOK, map[string]interface{}{
+		"ids": ids,
+	})
+}
+
+func (s *server) bulkDeleteItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		selection := &models.ItemSelection{}
+		if err := json.NewDecoder(r.Body).Decode(selection); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		ids, err := s.services.TodoItem.BulkDelete(r.Context(), u.ID, selection)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, map[string]interface{}{
+			"ids": ids,
+		})
+	}
+}
+
+func (s *server) copyItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		input := &models.TransferInput{}
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		ids, err := s.services.TodoItem.Copy(r.Context(), u.ID, input)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, map[string]interface{}{
+			"ids": ids,
+		})
+	}
+}
+
+func (s *server) restoreItem() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		vars := mux.Vars(r)
+		itemId, err := strconv.Atoi(vars["itemId"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.services.TodoItem.Restore(r.Context(), userId, itemId); err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
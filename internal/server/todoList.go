@@ -2,9 +2,13 @@ package server
 
 import (
 	"Todo-app/internal/models"
+	"Todo-app/internal/service"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 )
 
@@ -28,8 +32,8 @@ func (s *server) handleTodosCreate() http.HandlerFunc {
 			Description: req.Description,
 		}
 
-		if _, err := s.services.TodoList.Create(userID, t); err != nil {
-			s.error(w, r, http.StatusUnprocessableEntity, err)
+		if _, err := s.services.TodoList.Create(r.Context(), userID, t); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -63,15 +67,92 @@ func (s *server) handleTodosUpdate() http.HandlerFunc {
 			Description: &req.Description,
 		}
 
-		if err := s.services.TodoList.Update(userID, id, t); err != nil {
-			s.error(w, r, http.StatusUnprocessableEntity, err)
+		t.Version, err = ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.writeList(w, r, userID, id, t)
+	}
+}
+
+// handleTodosPatch applies a JSON merge patch (RFC 7396) to a list: only the
+// fields in the patch change.
+func (s *server) handleTodosPatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := mergePatch(r)
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Is(err, errUnsupportedPatch) {
+				code = http.StatusUnsupportedMediaType
+			}
+			s.error(w, r, code, err)
+			return
+		}
+
+		t, err := models.ParseListMergePatch(body)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		t.Version, err = ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+		if t.Empty() {
+			s.keepList(w, r, userID, id, t.Version)
 			return
 		}
 
-		s.respond(w, r, http.StatusOK, t)
+		s.writeList(w, r, userID, id, t)
 	}
 }
 
+// writeList updates a list and responds with its new state.
+func (s *server) writeList(w http.ResponseWriter, r *http.Request, userID, id int, t *models.UpdateListInput) {
+	if err := s.services.TodoList.Update(r.Context(), userID, id, t); err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	list, err := s.services.TodoList.GetById(r.Context(), userID, id)
+	if err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.respondWritten(w, r, list.Version, list)
+}
+
+// keepList answers an empty merge patch: nothing is written and the list is
+// returned as it is, unless it has changed since the If-Match version.
+func (s *server) keepList(w http.ResponseWriter, r *http.Request, userID, id int, version *int) {
+	list, err := s.services.TodoList.GetById(r.Context(), userID, id)
+	if err != nil {
+		s.error(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if version != nil && *version != list.Version {
+		s.error(w, r, http.StatusPreconditionFailed, service.ErrVersionMismatch)
+		return
+	}
+
+	s.respondWritten(w, r, list.Version, list)
+}
+
 func (s *server) handleTodosDelete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -81,8 +162,14 @@ func (s *server) handleTodosDelete() http.HandlerFunc {
 			return
 		}
 
-		if err := s.services.TodoList.Delete(id, r.Context().Value(ctxKeyUser).(*models.User).ID); err != nil {
-			s.error(w, r, http.StatusUnprocessableEntity, err)
+		version, err := ifMatch(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.services.TodoList.Delete(r.Context(), r.Context().Value(ctxKeyUser).(*models.User).ID, id, version); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -102,27 +189,199 @@ func (s *server) getListById() http.HandlerFunc {
 			return
 		}
 
-		list, err := s.services.TodoList.GetById(userId, id)
+		list, err := s.services.TodoList.GetById(r.Context(), userId, id)
 		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		s.respond(w, r, http.StatusOK, list)
+		s.respondVersioned(w, r, list.Version, list)
 	}
 }
 
+// embeddedSmartLists is how many smart lists come with the first page of
+// lists. The others are paged through at smart_lists_next.
+const embeddedSmartLists = 20
+
 func (s *server) getAllLists() http.HandlerFunc {
+	type response struct {
+		*pageResponse
+		SmartLists     []*models.SmartList `json:"smart_lists,omitempty"`
+		SmartListsNext string              `json:"smart_lists_next,omitempty"`
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		u := r.Context().Value(ctxKeyUser).(*models.User)
 		userId := u.ID
 
-		lists, err := s.services.TodoList.GetAll(userId)
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		lists, info, err := s.services.TodoList.GetAll(r.Context(), userId, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		res := &response{pageResponse: newPage(w, r, lists, info)}
+
+		// smart lists come with the first page of real lists
+		if page.After == nil && page.Before == nil {
+			var smartInfo *models.PageInfo
+			res.SmartLists, smartInfo, err = s.services.SmartList.GetAll(r.Context(), userId, &models.PageRequest{Limit: embeddedSmartLists})
+			if err != nil {
+				s.error(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			if smartInfo.Next != nil {
+				// the smart list routes sit next to /todos under the same prefix
+				next := url.Values{"after": {smartInfo.Next.Encode()}}
+				res.SmartListsNext = path.Join(path.Dir(path.Clean(r.URL.Path)), "smart-lists") + "/?" + next.Encode()
+			}
+		}
+
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+func (s *server) handleTodosReorder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.PositionInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.TodoList.Reorder(r.Context(), userID, id, req); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleTodosDuplicate() http.HandlerFunc {
+	type request struct {
+		Title string `json:"title"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		list, err := s.services.TodoList.Duplicate(r.Context(), userID, id, req.Title)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusCreated, list)
+	}
+}
+
+func (s *server) handleTodosArchive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.TodoList.Archive(r.Context(), userID, id); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleTodosUnarchive() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.TodoList.Unarchive(r.Context(), userID, id); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) handleTodosRestore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		if err := s.services.TodoList.Restore(r.Context(), userID, id); err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *server) getArchivedLists() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+		userId := u.ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		lists, info, err := s.services.TodoList.GetArchived(r.Context(), userId, page)
 		if err != nil {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
 
-		s.respond(w, r, http.StatusOK, lists)
+		s.respondPage(w, r, lists, info)
 	}
 }
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"net/http"
+)
+
+func (s *server) handleTrashLists() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		lists, info, err := s.services.Trash.GetLists(r.Context(), userID, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, lists, info)
+	}
+}
+
+func (s *server) handleTrashItems() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(ctxKeyUser).(*models.User).ID
+
+		page, err := pageRequest(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		items, info, err := s.services.Trash.GetItems(r.Context(), userID, page)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respondPage(w, r, items, info)
+	}
+}
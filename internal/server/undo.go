@@ -0,0 +1,50 @@
+package server
+
+import (
+	"Todo-app/internal/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+func (s *server) handleUndo() http.HandlerFunc {
+	return s.handleUndoRedo(func(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error) {
+		return s.services.Undo.Undo(ctx, userId, input)
+	})
+}
+
+func (s *server) handleRedo() http.HandlerFunc {
+	return s.handleUndoRedo(func(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error) {
+		return s.services.Undo.Redo(ctx, userId, input)
+	})
+}
+
+func (s *server) handleUndoRedo(run func(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &models.UndoInput{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		u := r.Context().Value(ctxKeyUser).(*models.User)
+
+		ops, err := run(r.Context(), u.ID, req)
+		if err != nil {
+			// the steps before the failing one were applied and are reported
+			// along with the problem
+			p := newProblem(r, http.StatusInternalServerError, err)
+			w.Header().Set("Content-Type", "application/problem+json")
+			s.respond(w, r, p.Status, struct {
+				*problem
+				Operations []*models.Operation `json:"operations"`
+			}{p, ops})
+			return
+		}
+
+		s.respond(w, r, http.StatusOK, map[string]interface{}{
+			"operations": ops,
+		})
+	}
+}
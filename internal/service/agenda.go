@@ -0,0 +1,130 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"time"
+)
+
+type AgendaService struct {
+	repo repository.Agenda
+}
+
+func NewAgendaService(repo repository.Agenda) *AgendaService {
+	return &AgendaService{repo: repo}
+}
+
+// Today returns the items due today.
+func (s *AgendaService) Today(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error) {
+	return s.days(ctx, userId, input, func(today time.Time) (time.Time, time.Time, error) {
+		return today, today, nil
+	})
+}
+
+// Upcoming returns the items due in the input.Days days after today.
+func (s *AgendaService) Upcoming(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error) {
+	return s.days(ctx, userId, input, func(today time.Time) (time.Time, time.Time, error) {
+		days := input.Days
+		if days == 0 {
+			days = models.DefaultUpcomingDays
+		}
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, days), nil
+	})
+}
+
+// Range returns the items due between input.From and input.To.
+func (s *AgendaService) Range(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error) {
+	return s.days(ctx, userId, input, func(today time.Time) (time.Time, time.Time, error) {
+		return input.Range(today)
+	})
+}
+
+// Overdue returns the items that are past due and not done, whenever they
+// were due.
+func (s *AgendaService) Overdue(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	loc, err := time.LoadLocation(input.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	days, err := s.repo.Get(ctx, userId, &models.AgendaQuery{
+		End:      now,
+		TimeZone: input.TimeZone,
+		Limit:    agendaLimit(input),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newAgenda(input.TimeZone, "", now.Format(time.DateOnly), days), nil
+}
+
+// days builds the agenda of every day from the first to the last day span
+// returns, including days without any items.
+func (s *AgendaService) days(ctx context.Context, userId int, input *models.AgendaInput, span func(today time.Time) (time.Time, time.Time, error)) (*models.Agenda, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	loc, err := time.LoadLocation(input.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	y, m, d := time.Now().In(loc).Date()
+	first, last, err := span(time.Date(y, m, d, 0, 0, 0, 0, loc))
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	start := first
+	found, err := s.repo.Get(ctx, userId, &models.AgendaQuery{
+		Start:       &start,
+		End:         last.AddDate(0, 0, 1),
+		TimeZone:    input.TimeZone,
+		IncludeDone: input.IncludeDone,
+		Limit:       agendaLimit(input),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]*models.AgendaDay, len(found))
+	for _, day := range found {
+		byDate[day.Date] = day
+	}
+
+	days := make([]*models.AgendaDay, 0)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		if byDate[date] == nil {
+			byDate[date] = &models.AgendaDay{Date: date, Items: make([]*models.ToDoItem, 0)}
+		}
+		days = append(days, byDate[date])
+	}
+
+	return newAgenda(input.TimeZone, first.Format(time.DateOnly), last.Format(time.DateOnly), days), nil
+}
+
+func newAgenda(timeZone, from, to string, days []*models.AgendaDay) *models.Agenda {
+	agenda := &models.Agenda{TimeZone: timeZone, From: from, To: to, Days: days}
+	for _, day := range days {
+		agenda.Count += day.Count
+	}
+
+	return agenda
+}
+
+func agendaLimit(input *models.AgendaInput) int {
+	if input.Limit == 0 {
+		return models.DefaultPageSize
+	}
+
+	return input.Limit
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+)
+
+type AuditService struct {
+	repo   repository.Audit
+	ops    repository.Operation
+	outbox repository.Outbox
+}
+
+func NewAuditService(repo repository.Audit, ops repository.Operation, outbox repository.Outbox) *AuditService {
+	return &AuditService{repo: repo, ops: ops, outbox: outbox}
+}
+
+// auditOf returns an audit service that records into the repositories, and so
+// into their transaction, if they share one.
+func auditOf(repos *repository.Repository) *AuditService {
+	return NewAuditService(repos.Audit, repos.Operation, repos.Outbox)
+}
+
+func (s *AuditService) GetByList(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	return s.repo.GetByList(ctx, userId, listId, page)
+}
+
+func (s *AuditService) GetByItem(ctx context.Context, userId, itemId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	return s.repo.GetByItem(ctx, userId, itemId, page)
+}
+
+func (s *AuditService) Find(ctx context.Context, filter *models.AuditFilter, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error) {
+	return s.repo.Find(ctx, filter, page)
+}
+
+// record appends the changes of one user action to the audit log and pushes
+// them as a single step onto the actor's undo stack.
+func (s *AuditService) record(ctx context.Context, actorId int, changes ...*models.AuditEntry) error {
+	ids, err := s.write(ctx, actorId, changes)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	return s.ops.Push(ctx, actorId, ids)
+}
+
+// write appends changes to the audit log, and their domain events to the
+// outbox, without touching the undo stack. Updates that didn't change anything
+// are skipped.
+func (s *AuditService) write(ctx context.Context, actorId int, changes []*models.AuditEntry) ([]int64, error) {
+	ids := make([]int64, 0, len(changes))
+
+	for _, entry := range changes {
+		if len(entry.Changes) == 0 && entry.Action != models.AuditCreate && entry.Action != models.AuditDelete {
+			continue
+		}
+
+		entry.ActorID = actorId
+		if err := s.repo.Record(ctx, entry); err != nil {
+			return nil, err
+		}
+		if err := s.outbox.Append(ctx, models.EventsOf(entry)...); err != nil {
+			return nil, err
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	return ids, nil
+}
+
+// listChange describes a change of a list between two snapshots.
+func listChange(action string, listId int, before, after map[string]interface{}) *models.AuditEntry {
+	return &models.AuditEntry{
+		Action:   action,
+		Entity:   models.AuditEntityList,
+		EntityID: listId,
+		ListID:   listId,
+		Changes:  models.Diff(before, after),
+	}
+}
+
+// itemChange describes a change of an item; the item's list is taken from
+// the latest snapshot available.
+func itemChange(action string, before, after *models.ToDoItem) *models.AuditEntry {
+	item := after
+	if item == nil {
+		item = before
+	}
+
+	return &models.AuditEntry{
+		Action:   action,
+		Entity:   models.AuditEntityItem,
+		EntityID: item.ID,
+		ListID:   item.ListID,
+		Changes:  models.Diff(before.AuditFields(), after.AuditFields()),
+	}
+}
+
+// listCreated describes a list that was created together with its items.
+func listCreated(list *models.ToDoList, items []*models.ToDoItem) []*models.AuditEntry {
+	changes := []*models.AuditEntry{listChange(models.AuditCreate, list.ID, nil, list.AuditFields())}
+
+	for _, item := range items {
+		changes = append(changes, itemChange(models.AuditCreate, nil, item))
+	}
+
+	return changes
+}
+
+func deletedFlag(before, after bool) (map[string]interface{}, map[string]interface{}) {
+	return map[string]interface{}{"deleted": before}, map[string]interface{}{"deleted": after}
+}
+
+func archivedFlag(before, after bool) (map[string]interface{}, map[string]interface{}) {
+	return map[string]interface{}{"archived": before}, map[string]interface{}{"archived": after}
+}
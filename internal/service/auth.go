@@ -3,6 +3,7 @@ package service
 import (
 	"Todo-app/internal/models"
 	"Todo-app/internal/repository"
+	"context"
 )
 
 type AuthService struct {
@@ -13,14 +14,27 @@ func NewAuthService(repo repository.Authorization) *AuthService {
 	return &AuthService{repo: repo}
 }
 
-func (s *AuthService) CreateUser(user *models.User) (*models.User, error) {
-	return s.repo.Create(user)
+func (s *AuthService) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+	return s.repo.Create(ctx, user)
 }
 
-func (s *AuthService) FindByEmail(email string) (*models.User, error) {
-	return s.repo.FindByEmail(email)
+func (s *AuthService) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.repo.FindByEmail(ctx, email)
 }
 
-func (s *AuthService) Find(id int) (*models.User, error) {
-	return s.repo.Find(id)
+func (s *AuthService) Find(ctx context.Context, id int) (*models.User, error) {
+	return s.repo.Find(ctx, id)
+}
+
+func (s *AuthService) FindByIds(ctx context.Context, ids []int) ([]*models.User, error) {
+	return s.repo.FindByIds(ctx, ids)
+}
+
+func (s *AuthService) SetTimeZone(ctx context.Context, id int, timeZone string) error {
+	input := &models.TimeZoneInput{TimeZone: timeZone}
+	if err := input.Validate(); err != nil {
+		return invalid(err)
+	}
+
+	return s.repo.SetTimeZone(ctx, id, timeZone)
 }
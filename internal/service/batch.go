@@ -0,0 +1,31 @@
+package service
+
+import (
+	"Todo-app/internal/repository"
+	"context"
+)
+
+type BatchService struct {
+	repos      *repository.Repository
+	newService func(repos *repository.Repository) *Service
+}
+
+func NewBatchService(repos *repository.Repository, newService func(repos *repository.Repository) *Service) *BatchService {
+	return &BatchService{repos: repos, newService: newService}
+}
+
+// Run calls fn with services whose changes all go into one transaction,
+// which is committed when fn returns nil and rolled back otherwise. fn is
+// called again if the transaction has to be retried.
+func (s *BatchService) Run(ctx context.Context, fn func(ctx context.Context, services *Service) error) error {
+	return inTx(ctx, s.repos, s.newService, fn)
+}
+
+// inTx runs fn with a copy of a service, made by bind, whose repositories
+// share one transaction, so that a change and its audit entries are saved
+// together.
+func inTx[S any](ctx context.Context, repos *repository.Repository, bind func(repos *repository.Repository) S, fn func(ctx context.Context, tx S) error) error {
+	return repos.Transact(ctx, func(ctx context.Context, repos *repository.Repository) error {
+		return fn(ctx, bind(repos))
+	})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Kind is the class of a domain error, which decides how it is reported.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindForbidden
+	KindConflict
+	KindPrecondition
+	KindGone
+	KindTimeout
+)
+
+// Error is a failure the client can act on. Code is stable and meant to be
+// switched on; Message is for humans. Fields names the invalid fields of the
+// input, if any.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same code, so a wrapped error still matches the
+// sentinel it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	ErrNotFound  = &Error{Kind: KindNotFound, Code: "not_found", Message: "the resource doesn't exist"}
+	ErrForbidden = &Error{Kind: KindForbidden, Code: "forbidden", Message: "only the owner can change this resource"}
+	ErrConflict  = &Error{Kind: KindConflict, Code: "conflict", Message: "the data was changed in the meantime"}
+	ErrTimeout   = &Error{Kind: KindTimeout, Code: "timeout", Message: "the request took too long and was canceled"}
+
+	ErrTooManyItems = &Error{Kind: KindInvalid, Code: "too_many_items", Message: fmt.Sprintf("the query matches more than %d items", models.MaxBulkItems)}
+	ErrEmailTaken   = &Error{Kind: KindConflict, Code: "email_taken", Message: "email is already registered"}
+
+	// ErrVersionMismatch is returned when a conditional change was based on
+	// an outdated version of a list or an item.
+	ErrVersionMismatch = &Error{Kind: KindPrecondition, Code: "version_mismatch", Message: "the resource has changed since the given version"}
+)
+
+// invalid turns a failed validation of the input into a domain error, with
+// the offending fields when the validation names them.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	e = &Error{Kind: KindInvalid, Code: "validation_failed", Message: err.Error(), Err: err}
+
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		e.Message = "the input is invalid"
+		e.Fields = make(map[string]string, len(fields))
+		for name, fieldErr := range fields {
+			e.Fields[name] = fieldErr.Error()
+		}
+	}
+
+	var filterErr *models.FilterError
+	if errors.As(err, &filterErr) {
+		e.Code = "invalid_filter"
+	}
+
+	return e
+}
+
+// AsError returns the domain error behind err. Errors of the storage layer
+// that the services pass through are translated; anything else is internal.
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return wrap(ErrNotFound, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return wrap(ErrTimeout, err)
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return wrap(ErrVersionMismatch, err)
+	case errors.Is(err, repository.ErrConflict), repository.Retryable(err):
+		// a transaction that still collides after its retries is a conflict
+		// the client can retry, not a failure of the server
+		return wrap(ErrConflict, err)
+	case errors.Is(err, repository.ErrEmailTaken):
+		return wrap(ErrEmailTaken, err)
+	case errors.Is(err, repository.ErrPositionSelf):
+		return invalid(err).(*Error)
+	case errors.As(err, &validation.Errors{}), errors.As(err, new(*models.FilterError)):
+		return invalid(err).(*Error)
+	}
+
+	return &Error{Kind: KindInternal, Code: "internal", Message: "internal server error", Err: err}
+}
+
+// wrap returns a copy of the sentinel e that records the error behind it.
+func wrap(e *Error, err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
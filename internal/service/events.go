@@ -0,0 +1,111 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"time"
+)
+
+type EventService struct {
+	notifications repository.Notifications
+	lists         repository.TodoList
+	audit         repository.Audit
+}
+
+func NewEventService(notifications repository.Notifications, lists repository.TodoList, audit repository.Audit) *EventService {
+	return &EventService{notifications: notifications, lists: lists, audit: audit}
+}
+
+// Subscribe follows the changes of the lists the user can access, or of one
+// of them if listId isn't 0, after the change with the given id or, without
+// one, from now on. It stops following them when ctx is done.
+func (s *EventService) Subscribe(ctx context.Context, userId, listId int, after *int64) (*Subscription, error) {
+	if listId != 0 {
+		if _, err := s.lists.GetById(ctx, userId, listId); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{audit: s.audit, userId: userId, listId: listId, wake: make(chan struct{}, 1)}
+
+	// subscribe before reading the latest change, so that nothing committed
+	// in between is missed
+	sub.unsubscribe = s.notifications.Subscribe(ctx, listId, sub.wake)
+
+	var err error
+	if after != nil {
+		sub.after, err = s.audit.ChangePosition(ctx, *after)
+	} else {
+		sub.after, err = s.audit.LatestPosition(ctx)
+	}
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	// the changes made before subscribing are read right away
+	sub.signal()
+	return sub, nil
+}
+
+// changeRecheck is how soon a subscription looks again for changes that were
+// held back by an older transaction. That transaction may end without
+// writing to the audit log, and then no notification announces it.
+const changeRecheck = time.Second
+
+// Subscription follows the changes a user can see, ordered by the
+// transactions that made them. It isn't safe for concurrent use.
+type Subscription struct {
+	audit       repository.Audit
+	userId      int
+	listId      int
+	after       models.ChangePosition
+	wake        chan struct{}
+	recheck     *time.Timer
+	unsubscribe func()
+}
+
+// Ready receives a value when there may be changes to read with Next.
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.wake
+}
+
+// Next returns up to one page of the changes after the last one it returned.
+func (s *Subscription) Next(ctx context.Context) ([]*models.AuditEntry, error) {
+	page, err := s.audit.GetChanges(ctx, s.userId, s.listId, s.after, models.MaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.after = page.Next
+
+	// a full page means that more changes are waiting; changes held back by
+	// an older transaction are looked for again a little later
+	switch {
+	case len(page.Entries) == models.MaxPageSize:
+		s.signal()
+	case page.Waiting:
+		if s.recheck == nil {
+			s.recheck = time.AfterFunc(changeRecheck, s.signal)
+		} else {
+			s.recheck.Reset(changeRecheck)
+		}
+	}
+
+	return page.Entries, nil
+}
+
+func (s *Subscription) Close() {
+	s.unsubscribe()
+	if s.recheck != nil {
+		s.recheck.Stop()
+	}
+}
+
+func (s *Subscription) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
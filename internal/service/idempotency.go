@@ -0,0 +1,70 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"time"
+)
+
+var (
+	ErrIdempotencyMismatch = &Error{Kind: KindInvalid, Code: "idempotency_key_reused", Message: "the idempotency key was used for a different request"}
+	ErrRequestInProgress   = &Error{Kind: KindConflict, Code: "request_in_progress", Message: "a request with this idempotency key is still in progress"}
+)
+
+// idempotencyLockTimeout is how long a request may hold its key before it is
+// taken to have failed without releasing it, e.g. because the server stopped.
+const idempotencyLockTimeout = time.Minute
+
+type IdempotencyService struct {
+	repo      repository.Idempotency
+	retention time.Duration
+}
+
+// NewIdempotencyService keeps the responses of requests for retention, during
+// which retries get them again.
+func NewIdempotencyService(repo repository.Idempotency, retention time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, retention: retention}
+}
+
+// Begin claims a key for a request with the given fingerprint. It returns nil
+// when the request should run, or the stored response of the earlier request
+// with the same key and fingerprint.
+func (s *IdempotencyService) Begin(ctx context.Context, userId int, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	input := &models.IdempotencyKeyInput{Key: key}
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	now := time.Now()
+	record, err := s.repo.Claim(ctx, userId, key, fingerprint, now.Add(-s.retention), now.Add(-idempotencyLockTimeout))
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyMismatch
+	}
+
+	if record.CompletedAt == nil {
+		return nil, ErrRequestInProgress
+	}
+
+	return record, nil
+}
+
+// Complete stores the response of a request that Begin let run.
+func (s *IdempotencyService) Complete(ctx context.Context, userId int, key string, record *models.IdempotencyRecord) error {
+	return s.repo.Complete(ctx, userId, key, record)
+}
+
+// Release frees the key of a request that failed, so a retry runs it again.
+func (s *IdempotencyService) Release(ctx context.Context, userId int, key, fingerprint string) error {
+	return s.repo.Release(ctx, userId, key, fingerprint)
+}
+
+// Purge deletes the keys that are older than the retention period and
+// returns their number.
+func (s *IdempotencyService) Purge(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-s.retention))
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type ListTemplateService struct {
+	repos    *repository.Repository
+	repo     repository.ListTemplate
+	itemRepo repository.TodoItem
+	audit    *AuditService
+}
+
+func NewListTemplateService(repos *repository.Repository) *ListTemplateService {
+	return &ListTemplateService{repos: repos, repo: repos.ListTemplate, itemRepo: repos.TodoItem, audit: auditOf(repos)}
+}
+
+func (s *ListTemplateService) CreateFromList(ctx context.Context, userId, listId int, input *models.TemplateInput) (*models.ListTemplate, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	return s.repo.CreateFromList(ctx, userId, listId, input)
+}
+
+func (s *ListTemplateService) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ListTemplate, *models.PageInfo, error) {
+	return s.repo.GetAll(ctx, userId, page)
+}
+
+func (s *ListTemplateService) GetById(ctx context.Context, userId, templateId int) (*models.ListTemplate, error) {
+	return s.repo.GetById(ctx, userId, templateId)
+}
+
+// Delete removes one of the user's templates. Shared templates of other users
+// can be used but not deleted.
+func (s *ListTemplateService) Delete(ctx context.Context, userId, templateId int) error {
+	err := s.repo.Delete(ctx, userId, templateId)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := s.repo.GetById(ctx, userId, templateId); getErr == nil {
+			return ErrForbidden
+		}
+	}
+
+	return err
+}
+
+func (s *ListTemplateService) Instantiate(ctx context.Context, userId, templateId int, input *models.InstantiateInput) (*models.ToDoList, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	var list *models.ToDoList
+	err := inTx(ctx, s.repos, NewListTemplateService, func(ctx context.Context, tx *ListTemplateService) error {
+		var err error
+		if list, err = tx.repo.Instantiate(ctx, userId, templateId, input); err != nil {
+			return err
+		}
+
+		items, err := allItems(ctx, tx.itemRepo, userId, list.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, listCreated(list, items)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
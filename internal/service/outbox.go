@@ -0,0 +1,134 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// outboxBatch is how many events one round of the relay publishes.
+	outboxBatch = 100
+	// outboxLease is how long a claimed event waits before another relay may
+	// publish it.
+	outboxLease = time.Minute
+	// An event some subscriber failed is published again after outboxBackoff,
+	// doubling with every further attempt up to outboxMaxBackoff.
+	outboxBackoff    = time.Second
+	outboxMaxBackoff = 10 * time.Minute
+)
+
+// Subscriber handles a domain event. Events may be delivered more than once,
+// so subscribers have to be idempotent; an error makes the relay deliver the
+// event again later.
+type Subscriber func(ctx context.Context, event *models.DomainEvent) error
+
+type subscription struct {
+	fn    Subscriber
+	types map[string]bool
+}
+
+type OutboxService struct {
+	repo      repository.Outbox
+	retention time.Duration
+
+	mu            sync.RWMutex
+	subscriptions []*subscription
+}
+
+// NewOutboxService keeps published events for retention.
+func NewOutboxService(repo repository.Outbox, retention time.Duration) *OutboxService {
+	return &OutboxService{repo: repo, retention: retention}
+}
+
+// Subscribe registers fn for the events of the given types, or of every type
+// if none are given.
+func (s *OutboxService) Subscribe(fn Subscriber, types ...string) {
+	sub := &subscription{fn: fn}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions = append(s.subscriptions, sub)
+}
+
+// Relay publishes a batch of due events to the subscribers, oldest first, and
+// returns how many it published. An event is marked as published once every
+// subscriber took it; otherwise all of them get it again after a backoff.
+func (s *OutboxService) Relay(ctx context.Context) (int, error) {
+	events, err := s.repo.Claim(ctx, outboxBatch, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := s.publish(ctx, event); err != nil {
+			log.Printf("event %d (%s) failed, attempt %d: %v", event.ID, event.Type, event.Attempts, err)
+
+			if err := s.repo.Retry(ctx, event.ID, time.Now().Add(outboxDelay(event.Attempts))); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		if err := s.repo.Publish(ctx, event.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// publish hands an event to every subscriber of its type and returns the
+// first error.
+func (s *OutboxService) publish(ctx context.Context, event *models.DomainEvent) error {
+	s.mu.RLock()
+	subscriptions := s.subscriptions
+	s.mu.RUnlock()
+
+	var first error
+	for _, sub := range subscriptions {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+
+		if err := deliver(ctx, sub.fn, event); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// deliver calls a subscriber, turning a panic into an error.
+func deliver(ctx context.Context, fn Subscriber, event *models.DomainEvent) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("subscriber panicked: %v", v)
+		}
+	}()
+
+	return fn(ctx, event)
+}
+
+func outboxDelay(attempts int) time.Duration {
+	if attempts > 20 {
+		return outboxMaxBackoff
+	}
+
+	return min(outboxBackoff<<(attempts-1), outboxMaxBackoff)
+}
+
+// Purge removes the events published longer ago than the retention period.
+func (s *OutboxService) Purge(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-s.retention))
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+)
+
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.SearchResult, *models.PageInfo, error) {
+	input := &models.SearchInput{Query: query}
+	if err := input.Validate(); err != nil {
+		return nil, nil, invalid(err)
+	}
+
+	return s.repo.Find(ctx, userId, input.Query, page)
+}
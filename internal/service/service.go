@@ -3,40 +3,204 @@ package service
 import (
 	"Todo-app/internal/models"
 	"Todo-app/internal/repository"
+	"context"
+	"time"
 )
 
 type Authorization interface {
-	CreateUser(user *models.User) (*models.User, error)
-	FindByEmail(email string) (*models.User, error)
-	Find(id int) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Find(ctx context.Context, id int) (*models.User, error)
+	FindByIds(ctx context.Context, ids []int) ([]*models.User, error)
+	SetTimeZone(ctx context.Context, id int, timeZone string) error
 }
 
 type TodoList interface {
-	Create(userId int, list *models.ToDoList) (int, error)
-	GetAll(userId int) ([]*models.ToDoList, error)
-	GetById(userId, listId int) (*models.ToDoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input *models.UpdateListInput) error
+	Create(ctx context.Context, userId int, list *models.ToDoList) (int, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, listId int) (*models.ToDoList, error)
+	GetByIds(ctx context.Context, userId int, listIds []int) ([]*models.ToDoList, error)
+	Delete(ctx context.Context, userId, listId int, version *int) error
+	Update(ctx context.Context, userId, listId int, input *models.UpdateListInput) error
+	Reorder(ctx context.Context, userId, listId int, input *models.PositionInput) error
+	Duplicate(ctx context.Context, userId, listId int, title string) (*models.ToDoList, error)
+	Restore(ctx context.Context, userId, listId int) error
+	Archive(ctx context.Context, userId, listId int) error
+	Unarchive(ctx context.Context, userId, listId int) error
+	GetArchived(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error)
 }
 
 type TodoItem interface {
-	Create(userId, listId int, item *models.ToDoItem) (int, error)
-	GetAll(userId, listId int) ([]*models.ToDoItem, error)
-	GetById(userId, itemId int) (*models.ToDoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input *models.UpdateItemInput) error
+	Create(ctx context.Context, userId, listId int, item *models.ToDoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error)
+	GetFirstPages(ctx context.Context, userId int, listIds []int, limit int) (map[int]*models.ItemPage, error)
+	Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, itemId int) (*models.ToDoItem, error)
+	Delete(ctx context.Context, userId, itemId int, version *int) error
+	Update(ctx context.Context, userId, itemId int, input *models.UpdateItemInput) error
+	BulkUpdate(ctx context.Context, userId int, selection *models.ItemSelection, input *models.UpdateItemInput) ([]int, error)
+	BulkDelete(ctx context.Context, userId int, selection *models.ItemSelection) ([]int, error)
+	Reorder(ctx context.Context, userId, listId, itemId int, input *models.PositionInput) error
+	Move(ctx context.Context, userId int, input *models.TransferInput) ([]int, error)
+	Copy(ctx context.Context, userId int, input *models.TransferInput) ([]int, error)
+	Restore(ctx context.Context, userId, itemId int) error
+}
+
+type ListTemplate interface {
+	CreateFromList(ctx context.Context, userId, listId int, input *models.TemplateInput) (*models.ListTemplate, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ListTemplate, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, templateId int) (*models.ListTemplate, error)
+	Delete(ctx context.Context, userId, templateId int) error
+	Instantiate(ctx context.Context, userId, templateId int, input *models.InstantiateInput) (*models.ToDoList, error)
+}
+
+type Trash interface {
+	GetLists(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedList, *models.PageInfo, error)
+	GetItems(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedItem, *models.PageInfo, error)
+	Purge(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type Audit interface {
+	GetByList(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+	GetByItem(ctx context.Context, userId, itemId int, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+	Find(ctx context.Context, filter *models.AuditFilter, page *models.PageRequest) ([]*models.AuditEntry, *models.PageInfo, error)
+}
+
+type Undo interface {
+	Undo(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error)
+	Redo(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error)
+}
+
+type SmartList interface {
+	Create(ctx context.Context, userId int, input *models.SmartListInput) (*models.SmartList, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.SmartList, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, smartListId int) (*models.SmartList, error)
+	Update(ctx context.Context, userId, smartListId int, input *models.SmartListInput) error
+	Delete(ctx context.Context, userId, smartListId int) error
+	GetItems(ctx context.Context, userId, smartListId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error)
+}
+
+type Agenda interface {
+	Today(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error)
+	Upcoming(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error)
+	Overdue(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error)
+	Range(ctx context.Context, userId int, input *models.AgendaInput) (*models.Agenda, error)
+}
+
+type Search interface {
+	Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.SearchResult, *models.PageInfo, error)
+}
+
+type Idempotency interface {
+	Begin(ctx context.Context, userId int, key, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, userId int, key string, record *models.IdempotencyRecord) error
+	Release(ctx context.Context, userId int, key, fingerprint string) error
+	Purge(ctx context.Context) (int64, error)
+}
+
+type Token interface {
+	Create(ctx context.Context, userId int, input *models.TokenInput) (*models.APIToken, string, error)
+	GetAll(ctx context.Context, userId int) ([]*models.APIToken, error)
+	Authenticate(ctx context.Context, secret string) (*models.User, error)
+	Revoke(ctx context.Context, userId, tokenId int) error
+}
+
+type Events interface {
+	Subscribe(ctx context.Context, userId, listId int, after *int64) (*Subscription, error)
+}
+
+type Sync interface {
+	Changes(ctx context.Context, userId int, token string, limit int) (*models.SyncPage, error)
+	Push(ctx context.Context, userId int, input *models.SyncInput) ([]*models.SyncResult, error)
+	Purge(ctx context.Context) (int64, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, userId int, input *models.WebhookInput) (*models.Webhook, error)
+	GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.Webhook, *models.PageInfo, error)
+	GetById(ctx context.Context, userId, hookId int) (*models.Webhook, error)
+	Update(ctx context.Context, userId, hookId int, input *models.UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, hookId int) error
+	GetDeliveries(ctx context.Context, userId, hookId int, status string, page *models.PageRequest) ([]*models.WebhookDelivery, *models.PageInfo, error)
+	Redeliver(ctx context.Context, userId, hookId int, deliveryId int64) (*models.WebhookDelivery, error)
+	Deliver(ctx context.Context, dbTimeout time.Duration) (int, error)
+	Purge(ctx context.Context) (int64, error)
+}
+
+type Outbox interface {
+	Subscribe(fn Subscriber, types ...string)
+	Relay(ctx context.Context) (int, error)
+	Purge(ctx context.Context) (int64, error)
+}
+
+type Batch interface {
+	Run(ctx context.Context, fn func(ctx context.Context, services *Service) error) error
 }
 
 type Service struct {
 	Authorization
 	TodoList
 	TodoItem
+	ListTemplate
+	Trash
+	Audit
+	Undo
+	Search
+	SmartList
+	Agenda
+	Idempotency
+	Token
+	Events
+	Sync
+	Webhook
+	Outbox
+	Batch
 }
 
-func NewService(repos *repository.Repository) *Service {
+// NewService wires the services together. notifications announce the changes
+// made by any instance of the server, undoWindow limits how far back users can
+// undo their changes, idempotencyRetention how long responses are kept for
+// retries, syncRetention how long clients can go without syncing,
+// webhookRetention how long the history of webhook deliveries is kept and
+// outboxRetention how long published domain events are kept.
+func NewService(repos *repository.Repository, notifications repository.Notifications, undoWindow, idempotencyRetention, syncRetention, webhookRetention, outboxRetention time.Duration) *Service {
+	newService := func(repos *repository.Repository) *Service {
+		return NewService(repos, notifications, undoWindow, idempotencyRetention, syncRetention, webhookRetention, outboxRetention)
+	}
+	webhook := NewWebhookService(repos.Webhook, repos.TodoList, repos.Authorization, webhookRetention)
+	outbox := NewOutboxService(repos.Outbox, outboxRetention)
+	outbox.Subscribe(webhook.Enqueue)
+
 	return &Service{
 		Authorization: NewAuthService(repos.Authorization),
-		TodoList:      NewTodoListService(repos.TodoList),
-		TodoItem:      NewTodoItemService(repos.TodoItem, repos.TodoList),
+		TodoList:      NewTodoListService(repos),
+		TodoItem:      NewTodoItemService(repos),
+		ListTemplate:  NewListTemplateService(repos),
+		Trash:         NewTrashService(repos.Trash),
+		Audit:         auditOf(repos),
+		Undo:          NewUndoService(repos, undoWindow),
+		Search:        NewSearchService(repos.Search),
+		SmartList:     NewSmartListService(repos.SmartList, repos.TodoItem, repos.Authorization),
+		Agenda:        NewAgendaService(repos.Agenda),
+		Idempotency:   NewIdempotencyService(repos.Idempotency, idempotencyRetention),
+		Token:         NewTokenService(repos.Token),
+		Events:        NewEventService(notifications, repos.TodoList, repos.Audit),
+		Sync:          NewSyncService(repos, newService, syncRetention),
+		Webhook:       webhook,
+		Outbox:        outbox,
+		Batch:         NewBatchService(repos, newService),
 	}
 }
+
+// DBContext returns a context for database work that ends when timeout has
+// passed, or only when it's canceled if timeout is 0. Requests and unary calls
+// get one for all of their work; streams run for as long as the client stays,
+// so they take one for each of their queries instead, and so do background
+// jobs for each round.
+func DBContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
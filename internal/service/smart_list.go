@@ -0,0 +1,95 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type SmartListService struct {
+	repo     repository.SmartList
+	itemRepo repository.TodoItem
+	users    repository.Authorization
+}
+
+func NewSmartListService(repo repository.SmartList, itemRepo repository.TodoItem, users repository.Authorization) *SmartListService {
+	return &SmartListService{repo: repo, itemRepo: itemRepo, users: users}
+}
+
+func (s *SmartListService) Create(ctx context.Context, userId int, input *models.SmartListInput) (*models.SmartList, error) {
+	if err := s.validate(ctx, userId, input); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, userId, input)
+}
+
+func (s *SmartListService) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.SmartList, *models.PageInfo, error) {
+	return s.repo.GetAll(ctx, userId, page)
+}
+
+func (s *SmartListService) GetById(ctx context.Context, userId, smartListId int) (*models.SmartList, error) {
+	return s.repo.GetById(ctx, userId, smartListId)
+}
+
+func (s *SmartListService) Update(ctx context.Context, userId, smartListId int, input *models.SmartListInput) error {
+	if err := s.validate(ctx, userId, input); err != nil {
+		return err
+	}
+
+	return s.owned(ctx, userId, smartListId, s.repo.Update(ctx, userId, smartListId, input))
+}
+
+func (s *SmartListService) Delete(ctx context.Context, userId, smartListId int) error {
+	return s.owned(ctx, userId, smartListId, s.repo.Delete(ctx, userId, smartListId))
+}
+
+// owned tells a change that failed because the smart list belongs to someone
+// else, who shared it, apart from one to a smart list that doesn't exist.
+func (s *SmartListService) owned(ctx context.Context, userId, smartListId int, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, getErr := s.repo.GetById(ctx, userId, smartListId); getErr == nil {
+			return ErrForbidden
+		}
+	}
+
+	return err
+}
+
+// GetItems evaluates a smart list for the user reading it.
+func (s *SmartListService) GetItems(ctx context.Context, userId, smartListId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error) {
+	list, err := s.repo.GetById(ctx, userId, smartListId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now, err := userNow(ctx, s.users, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter, err := models.ParseItemFilter(list.Query, userId, now)
+	if err != nil {
+		return nil, nil, invalid(err)
+	}
+
+	return s.itemRepo.Find(ctx, userId, filter, page)
+}
+
+// validate checks the input and that its query parses, so broken filters are
+// rejected when they are saved rather than when they are read.
+func (s *SmartListService) validate(ctx context.Context, userId int, input *models.SmartListInput) error {
+	if err := input.Validate(); err != nil {
+		return invalid(err)
+	}
+
+	now, err := userNow(ctx, s.users, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = models.ParseItemFilter(input.Query, userId, now)
+	return invalid(err)
+}
@@ -0,0 +1,511 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+var (
+	ErrInvalidSyncToken = &Error{Kind: KindInvalid, Code: "invalid_sync_token", Message: "the sync token is malformed"}
+	ErrSyncTokenExpired = &Error{Kind: KindGone, Code: "sync_token_expired", Message: "the sync token has expired, start over with a full sync"}
+	ErrUnknownListRef   = &Error{Kind: KindInvalid, Code: "unknown_list_ref", Message: "list_ref doesn't name a list created earlier in the push"}
+)
+
+const (
+	// tombstoneGrace keeps tombstones a while longer than the tokens that
+	// need them, for the transactions that were running when a token was
+	// issued.
+	tombstoneGrace = 24 * time.Hour
+	// maxSyncAttempts is how often a mutation is tried when the entity
+	// changes between reading and writing it.
+	maxSyncAttempts = 3
+)
+
+type SyncService struct {
+	repos      *repository.Repository
+	newService func(repos *repository.Repository) *Service
+	retention  time.Duration
+}
+
+// NewSyncService keeps tombstones for retention, which is how long a client
+// may stay offline and still catch up with a delta sync.
+func NewSyncService(repos *repository.Repository, newService func(repos *repository.Repository) *Service, retention time.Duration) *SyncService {
+	return &SyncService{repos: repos, newService: newService, retention: retention}
+}
+
+// Changes returns a page of the lists and items that changed since the token,
+// or of all of them for an empty token.
+func (s *SyncService) Changes(ctx context.Context, userId int, token string, limit int) (*models.SyncPage, error) {
+	page := &models.PageRequest{Limit: limit}
+	if err := page.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	now := time.Now()
+	t := &models.SyncToken{}
+	if token != "" {
+		decoded, err := models.DecodeSyncToken(token)
+		if err != nil {
+			return nil, wrap(ErrInvalidSyncToken, err)
+		}
+		t = decoded
+	}
+
+	if !t.Full() && time.Unix(t.Issued, 0).Before(now.Add(-s.retention)) {
+		return nil, ErrSyncTokenExpired
+	}
+
+	if t.Until == 0 {
+		horizon, err := s.repos.Sync.Horizon(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.Until, t.Started = horizon, now.Unix()
+	}
+
+	keys, err := s.repos.Sync.Changes(ctx, userId, t, page.Size()+1)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(keys) > page.Size()
+	if more {
+		keys = keys[:page.Size()]
+	}
+
+	changes, err := s.resolve(ctx, userId, keys, t.Full())
+	if err != nil {
+		return nil, err
+	}
+
+	next := &models.SyncToken{From: t.Until, Issued: t.Started}
+	if more {
+		last := keys[len(keys)-1]
+		next = &models.SyncToken{From: t.From, Issued: t.Issued, Until: t.Until, Started: t.Started, Kind: last.Kind, ID: last.ID}
+	}
+
+	return &models.SyncPage{Changes: changes, Token: next.Encode(), More: more}, nil
+}
+
+// resolve reads the current state of the changed lists and items. Those the
+// user can't see anymore become tombstones, or are left out of a full sync.
+func (s *SyncService) resolve(ctx context.Context, userId int, keys []*models.SyncKey, full bool) ([]*models.SyncChange, error) {
+	listIds := make([]int, 0, len(keys))
+	itemIds := make([]int, 0, len(keys))
+	for _, k := range keys {
+		if k.IsList() {
+			listIds = append(listIds, k.ID)
+		} else {
+			itemIds = append(itemIds, k.ID)
+		}
+	}
+
+	lists := make(map[int]*models.ToDoList, len(listIds))
+	if len(listIds) > 0 {
+		found, err := s.repos.TodoList.GetByIds(ctx, userId, listIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range found {
+			lists[l.ID] = l
+		}
+	}
+
+	items := make(map[int]*models.ToDoItem, len(itemIds))
+	if len(itemIds) > 0 {
+		found, err := s.repos.TodoItem.GetByIds(ctx, userId, itemIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range found {
+			items[i.ID] = i
+		}
+	}
+
+	changes := make([]*models.SyncChange, 0, len(keys))
+	for _, k := range keys {
+		change := &models.SyncChange{Entity: k.Entity(), ID: k.ID}
+		if k.IsList() {
+			change.List = lists[k.ID]
+		} else {
+			change.Item = items[k.ID]
+		}
+
+		if change.List == nil && change.Item == nil {
+			if full {
+				continue
+			}
+			change.Deleted = true
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// Push applies offline mutations in order, each in a transaction of its own.
+// Mutations that fail are reported in their result and don't stop the push.
+func (s *SyncService) Push(ctx context.Context, userId int, input *models.SyncInput) ([]*models.SyncResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	push := &syncPush{userId: userId, resolve: input.Resolve, refs: make(map[string]int)}
+	results := make([]*models.SyncResult, 0, len(input.Mutations))
+
+	for _, m := range input.Mutations {
+		result, err := s.apply(ctx, push, m)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// apply runs one mutation, again if the entity changed in the meantime. Only
+// internal errors are returned; the others fail the mutation.
+func (s *SyncService) apply(ctx context.Context, push *syncPush, m *models.SyncMutation) (*models.SyncResult, error) {
+	var result *models.SyncResult
+	var err error
+
+	for attempt := 0; attempt < maxSyncAttempts; attempt++ {
+		err = s.repos.Transact(ctx, func(ctx context.Context, repos *repository.Repository) error {
+			tx := &syncTx{syncPush: push, services: s.newService(repos), audit: repos.Audit}
+
+			var err error
+			result, err = tx.apply(ctx, m)
+			return err
+		})
+		if !errors.Is(err, repository.ErrVersionMismatch) {
+			break
+		}
+	}
+
+	if err != nil {
+		e := AsError(err)
+		if e.Kind == KindInternal {
+			return nil, err
+		}
+		return &models.SyncResult{
+			ID:       m.ID,
+			Status:   models.SyncStatusFailed,
+			EntityID: m.EntityID,
+			Error:    &models.SyncError{Code: e.Code, Message: e.Message, Fields: e.Fields},
+		}, nil
+	}
+
+	if m.Action == models.SyncCreate && m.Entity == models.AuditEntityList {
+		push.refs[m.ID] = result.EntityID
+	}
+
+	return result, nil
+}
+
+func (s *SyncService) Purge(ctx context.Context) (int64, error) {
+	return s.repos.Sync.Purge(ctx, time.Now().Add(-s.retention-tombstoneGrace))
+}
+
+// syncPush is the state of a push shared by its mutations: the ids of the
+// lists it created, by mutation.
+type syncPush struct {
+	userId  int
+	resolve string
+	refs    map[string]int
+}
+
+// syncTx applies a mutation with services that share a transaction.
+type syncTx struct {
+	*syncPush
+	services *Service
+	audit    repository.Audit
+}
+
+func (tx *syncTx) apply(ctx context.Context, m *models.SyncMutation) (*models.SyncResult, error) {
+	switch m.Action {
+	case models.SyncCreate:
+		return tx.create(ctx, m)
+	case models.SyncUpdate:
+		return tx.update(ctx, m)
+	default:
+		return tx.delete(ctx, m)
+	}
+}
+
+func (tx *syncTx) create(ctx context.Context, m *models.SyncMutation) (*models.SyncResult, error) {
+	result := &models.SyncResult{ID: m.ID, Status: models.SyncStatusApplied}
+
+	if m.Entity == models.AuditEntityList {
+		input, err := models.ParseListMergePatch(m.Set)
+		if err != nil {
+			return nil, invalid(err)
+		}
+
+		list := (&models.ToDoList{}).Apply(input)
+		if err := list.Validate(); err != nil {
+			return nil, invalid(err)
+		}
+
+		if result.EntityID, err = tx.services.TodoList.Create(ctx, tx.userId, list); err != nil {
+			return nil, err
+		}
+		return result, tx.state(ctx, m.Entity, result)
+	}
+
+	listId := m.ListID
+	if m.ListRef != "" {
+		id, ok := tx.refs[m.ListRef]
+		if !ok {
+			return nil, ErrUnknownListRef
+		}
+		listId = id
+	}
+
+	input, err := models.ParseItemMergePatch(m.Set)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if input.Title == nil {
+		return nil, invalid(validation.Errors{"title": errors.New("cannot be blank")})
+	}
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	if result.EntityID, err = tx.services.TodoItem.Create(ctx, tx.userId, listId, (&models.ToDoItem{}).Apply(input)); err != nil {
+		return nil, err
+	}
+	return result, tx.state(ctx, m.Entity, result)
+}
+
+// update applies the fields of the mutation that don't conflict. A field
+// conflicts when the server's value differs from the client's base, or, for
+// fields without a base, when the client's version is outdated.
+func (tx *syncTx) update(ctx context.Context, m *models.SyncMutation) (*models.SyncResult, error) {
+	e, err := tx.load(ctx, m.Entity, m.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := models.PatchKeys(m.Set)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	sort.Strings(keys)
+
+	client, err := e.patched(m.Set)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	based := make(map[string]bool)
+	var base map[string]interface{}
+	if len(m.Base) > 0 {
+		baseKeys, err := models.PatchKeys(m.Base)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		for _, k := range baseKeys {
+			based[k] = true
+		}
+
+		if base, err = e.patched(m.Base); err != nil {
+			return nil, invalid(err)
+		}
+	}
+
+	stale := m.Version != nil && *m.Version != e.version
+	keep := make(map[string]bool, len(keys))
+	conflicts := make([]*models.SyncConflict, 0)
+	var changed map[string]time.Time
+
+	for _, field := range keys {
+		server := e.fields[field]
+		if reflect.DeepEqual(client[field], server) {
+			continue
+		}
+
+		if based[field] && reflect.DeepEqual(base[field], server) || !based[field] && !stale {
+			keep[field] = true
+			continue
+		}
+
+		conflict := &models.SyncConflict{Field: field, Client: client[field], Server: server, Winner: models.SyncWinnerServer}
+		if based[field] {
+			conflict.Base = base[field]
+		}
+		if tx.resolve == models.ResolveLastWriterWins {
+			if changed == nil {
+				if changed, err = tx.audit.LastChanged(ctx, m.Entity, m.EntityID); err != nil {
+					return nil, err
+				}
+			}
+			if m.ChangedAt.After(changed[field]) {
+				conflict.Winner = models.SyncWinnerClient
+				keep[field] = true
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	if len(keep) > 0 {
+		patch, err := models.FilterPatch(m.Set, keep)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		if err := e.update(patch); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &models.SyncResult{ID: m.ID, Status: syncStatus(conflicts), EntityID: m.EntityID}
+	if len(conflicts) > 0 {
+		result.Conflicts = conflicts
+	}
+	return result, tx.state(ctx, m.Entity, result)
+}
+
+// delete moves the entity to the trash unless the client's version is
+// outdated and the conflict goes to the server. Entities that are already
+// gone count as deleted.
+func (tx *syncTx) delete(ctx context.Context, m *models.SyncMutation) (*models.SyncResult, error) {
+	result := &models.SyncResult{ID: m.ID, Status: models.SyncStatusApplied, EntityID: m.EntityID}
+
+	e, err := tx.load(ctx, m.Entity, m.EntityID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Version != nil && *m.Version != e.version {
+		conflict := &models.SyncConflict{Field: "version", Base: *m.Version, Server: e.version, Winner: models.SyncWinnerServer}
+
+		if tx.resolve == models.ResolveLastWriterWins {
+			changed, err := tx.audit.LastChanged(ctx, m.Entity, m.EntityID)
+			if err != nil {
+				return nil, err
+			}
+
+			var last time.Time
+			for _, at := range changed {
+				if at.After(last) {
+					last = at
+				}
+			}
+			if m.ChangedAt.After(last) {
+				conflict.Winner = models.SyncWinnerClient
+			}
+		}
+
+		result.Conflicts = []*models.SyncConflict{conflict}
+		if conflict.Winner == models.SyncWinnerServer {
+			result.Status = models.SyncStatusConflict
+			return result, tx.state(ctx, m.Entity, result)
+		}
+	}
+
+	return result, e.delete()
+}
+
+// syncStatus is conflict when some of the client's changes weren't applied.
+func syncStatus(conflicts []*models.SyncConflict) string {
+	for _, c := range conflicts {
+		if c.Winner == models.SyncWinnerServer {
+			return models.SyncStatusConflict
+		}
+	}
+	return models.SyncStatusApplied
+}
+
+// syncEntity is a list or an item as a mutation sees it: its audit fields,
+// and how to change and delete it conditionally on its version.
+type syncEntity struct {
+	fields  map[string]interface{}
+	version int
+	patched func(patch []byte) (map[string]interface{}, error)
+	update  func(patch []byte) error
+	delete  func() error
+}
+
+func (tx *syncTx) load(ctx context.Context, entity string, id int) (*syncEntity, error) {
+	if entity == models.AuditEntityList {
+		list, err := tx.services.TodoList.GetById(ctx, tx.userId, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &syncEntity{
+			fields:  list.AuditFields(),
+			version: list.Version,
+			patched: func(patch []byte) (map[string]interface{}, error) {
+				input, err := models.ParseListMergePatch(patch)
+				if err != nil {
+					return nil, err
+				}
+				return list.Apply(input).AuditFields(), nil
+			},
+			update: func(patch []byte) error {
+				input, err := models.ParseListMergePatch(patch)
+				if err != nil {
+					return invalid(err)
+				}
+				input.Version = &list.Version
+				return tx.services.TodoList.Update(ctx, tx.userId, id, input)
+			},
+			delete: func() error {
+				return tx.services.TodoList.Delete(ctx, tx.userId, id, &list.Version)
+			},
+		}, nil
+	}
+
+	item, err := tx.services.TodoItem.GetById(ctx, tx.userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syncEntity{
+		fields:  item.AuditFields(),
+		version: item.Version,
+		patched: func(patch []byte) (map[string]interface{}, error) {
+			input, err := models.ParseItemMergePatch(patch)
+			if err != nil {
+				return nil, err
+			}
+			return item.Apply(input).AuditFields(), nil
+		},
+		update: func(patch []byte) error {
+			input, err := models.ParseItemMergePatch(patch)
+			if err != nil {
+				return invalid(err)
+			}
+			input.Version = &item.Version
+			return tx.services.TodoItem.Update(ctx, tx.userId, id, input)
+		},
+		delete: func() error {
+			return tx.services.TodoItem.Delete(ctx, tx.userId, id, &item.Version)
+		},
+	}, nil
+}
+
+// state adds the current state of the entity to the result.
+func (tx *syncTx) state(ctx context.Context, entity string, result *models.SyncResult) error {
+	var err error
+	if entity == models.AuditEntityList {
+		result.List, err = tx.services.TodoList.GetById(ctx, tx.userId, result.EntityID)
+	} else {
+		result.Item, err = tx.services.TodoItem.GetById(ctx, tx.userId, result.EntityID)
+	}
+	return err
+}
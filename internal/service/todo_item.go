@@ -3,38 +3,359 @@ package service
 import (
 	"Todo-app/internal/models"
 	"Todo-app/internal/repository"
+	"context"
+	"time"
 )
 
 type TodoItemService struct {
+	repos    *repository.Repository
 	repo     repository.TodoItem
 	listRepo repository.TodoList
+	users    repository.Authorization
+	audit    *AuditService
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
-	return &TodoItemService{repo: repo, listRepo: listRepo}
+func NewTodoItemService(repos *repository.Repository) *TodoItemService {
+	return &TodoItemService{repos: repos, repo: repos.TodoItem, listRepo: repos.TodoList, users: repos.Authorization, audit: auditOf(repos)}
 }
 
-func (s *TodoItemService) Create(userId, listId int, item *models.ToDoItem) (int, error) {
-	_, err := s.listRepo.GetById(userId, listId)
+func (s *TodoItemService) Create(ctx context.Context, userId, listId int, item *models.ToDoItem) (int, error) {
+	var id int
+	err := inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		if _, err := tx.listRepo.GetById(ctx, userId, listId); err != nil {
+			return err
+		}
+
+		var err error
+		if id, err = tx.repo.Create(ctx, listId, item); err != nil {
+			return err
+		}
+
+		after, err := tx.repo.GetById(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, itemChange(models.AuditCreate, nil, after))
+	})
+
+	return id, err
+}
+
+func (s *TodoItemService) GetAll(ctx context.Context, userId, listId int, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error) {
+	return s.repo.GetAll(ctx, userId, listId, page)
+}
+
+// GetFirstPages returns the first page of items of several lists at once.
+func (s *TodoItemService) GetFirstPages(ctx context.Context, userId int, listIds []int, limit int) (map[int]*models.ItemPage, error) {
+	page := &models.PageRequest{Limit: limit}
+	if err := page.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	return s.repo.GetFirstPages(ctx, userId, listIds, limit)
+}
+
+// Find evaluates a filter expression against all of the user's items.
+func (s *TodoItemService) Find(ctx context.Context, userId int, query string, page *models.PageRequest) ([]*models.ToDoItem, *models.PageInfo, error) {
+	now, err := userNow(ctx, s.users, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter, err := models.ParseItemFilter(query, userId, now)
 	if err != nil {
-		return 0, err
+		return nil, nil, invalid(err)
+	}
+
+	return s.repo.Find(ctx, userId, filter, page)
+}
+
+func (s *TodoItemService) GetById(ctx context.Context, userId, itemId int) (*models.ToDoItem, error) {
+	return s.repo.GetById(ctx, userId, itemId)
+}
+
+func (s *TodoItemService) Delete(ctx context.Context, userId, itemId int, version *int) error {
+	return inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		before, err := tx.repo.GetById(ctx, userId, itemId)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.repo.Delete(ctx, userId, itemId, version); err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, itemChange(models.AuditDelete, before, nil))
+	})
+}
+
+func (s *TodoItemService) Update(ctx context.Context, userId, itemId int, input *models.UpdateItemInput) error {
+	if err := input.Validate(); err != nil {
+		return invalid(err)
+	}
+
+	return inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		return tx.change(ctx, userId, []int{itemId}, models.AuditUpdate, func() error {
+			return tx.repo.Update(ctx, userId, itemId, input)
+		})
+	})
+}
+
+func (s *TodoItemService) Reorder(ctx context.Context, userId, listId, itemId int, input *models.PositionInput) error {
+	if err := input.Validate(); err != nil {
+		return invalid(err)
 	}
 
-	return s.repo.Create(listId, item)
+	return inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		return tx.change(ctx, userId, []int{itemId}, models.AuditReorder, func() error {
+			return tx.repo.Reorder(ctx, userId, listId, itemId, input)
+		})
+	})
 }
 
-func (s *TodoItemService) GetAll(userId, listId int) ([]*models.ToDoItem, error) {
-	return s.repo.GetAll(userId, listId)
+// Move moves the selected items into another list and returns their ids.
+func (s *TodoItemService) Move(ctx context.Context, userId int, input *models.TransferInput) ([]int, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	var ids []int
+	err := inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		transfer, err := tx.selectTransfer(ctx, userId, input)
+		if err != nil {
+			return err
+		}
+		if ids = transfer.ItemIDs; len(ids) == 0 {
+			return nil
+		}
+
+		return tx.change(ctx, userId, ids, models.AuditMove, func() error {
+			return tx.repo.Move(ctx, userId, transfer)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
-func (s *TodoItemService) GetById(userId, itemId int) (*models.ToDoItem, error) {
-	return s.repo.GetById(userId, itemId)
+func (s *TodoItemService) Copy(ctx context.Context, userId int, input *models.TransferInput) ([]int, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	var ids []int
+	err := inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		transfer, err := tx.selectTransfer(ctx, userId, input)
+		if err != nil {
+			return err
+		}
+		if ids = transfer.ItemIDs; len(ids) == 0 {
+			return nil
+		}
+
+		if ids, err = tx.repo.Copy(ctx, userId, transfer); err != nil {
+			return err
+		}
+
+		changes := make([]*models.AuditEntry, 0, len(ids))
+		for _, id := range ids {
+			after, err := tx.repo.GetById(ctx, userId, id)
+			if err != nil {
+				return err
+			}
+
+			changes = append(changes, itemChange(models.AuditCreate, nil, after))
+		}
+
+		return tx.audit.record(ctx, userId, changes...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error {
-	return s.repo.Delete(userId, itemId)
+// BulkUpdate makes the same change to every selected item and returns their
+// ids.
+func (s *TodoItemService) BulkUpdate(ctx context.Context, userId int, selection *models.ItemSelection, input *models.UpdateItemInput) ([]int, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	var ids []int
+	err := inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		var err error
+		if ids, err = tx.selectItems(ctx, userId, selection); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.change(ctx, userId, ids, models.AuditUpdate, func() error {
+			return tx.repo.BulkUpdate(ctx, userId, ids, input)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
-func (s *TodoItemService) Update(userId, itemId int, input *models.UpdateItemInput) error {
-	return s.repo.Update(userId, itemId, input)
+// BulkDelete moves every selected item to the trash and returns their ids.
+func (s *TodoItemService) BulkDelete(ctx context.Context, userId int, selection *models.ItemSelection) ([]int, error) {
+	var ids []int
+	err := inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		var err error
+		if ids, err = tx.selectItems(ctx, userId, selection); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		changes := make([]*models.AuditEntry, 0, len(ids))
+		for _, id := range ids {
+			before, err := tx.repo.GetById(ctx, userId, id)
+			if err != nil {
+				return err
+			}
+
+			changes = append(changes, itemChange(models.AuditDelete, before, nil))
+		}
+
+		if err := tx.repo.BulkDelete(ctx, userId, ids); err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, changes...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// selectItems returns the ids of the items a bulk operation applies to, each
+// once. A query may match at most models.MaxBulkItems items.
+func (s *TodoItemService) selectItems(ctx context.Context, userId int, selection *models.ItemSelection) ([]int, error) {
+	if err := selection.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	if selection.Query == "" {
+		ids := make([]int, 0, len(selection.IDs))
+		seen := make(map[int]bool, len(selection.IDs))
+		for _, id := range selection.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	now, err := userNow(ctx, s.users, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := models.ParseItemFilter(selection.Query, userId, now)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	items, info, err := s.repo.Find(ctx, userId, filter, &models.PageRequest{Limit: models.MaxBulkItems})
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Next != nil {
+		return nil, ErrTooManyItems
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids, nil
+}
+
+// userNow returns the current time in the user's time zone, which relative
+// dates in filters such as today or +7d are taken in.
+func userNow(ctx context.Context, users repository.Authorization, userId int) (time.Time, error) {
+	u, err := users.Find(ctx, userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Now().In(loc), nil
+}
+
+// selectTransfer returns the transfer of the items it selects, by id. The
+// input is left as it is, so that a transaction run again selects them anew.
+func (s *TodoItemService) selectTransfer(ctx context.Context, userId int, input *models.TransferInput) (*models.TransferInput, error) {
+	ids, err := s.selectItems(ctx, userId, input.Selection())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransferInput{ItemIDs: ids, ListID: input.ListID}, nil
+}
+
+func (s *TodoItemService) Restore(ctx context.Context, userId, itemId int) error {
+	return inTx(ctx, s.repos, NewTodoItemService, func(ctx context.Context, tx *TodoItemService) error {
+		if err := tx.repo.Restore(ctx, userId, itemId); err != nil {
+			return err
+		}
+
+		after, err := tx.repo.GetById(ctx, userId, itemId)
+		if err != nil {
+			return err
+		}
+
+		change := itemChange(models.AuditRestore, nil, after)
+		change.Changes = models.Diff(deletedFlag(true, false))
+		return tx.audit.record(ctx, userId, change)
+	})
+}
+
+// change runs fn and records the difference it made to every item.
+func (s *TodoItemService) change(ctx context.Context, userId int, itemIds []int, action string, fn func() error) error {
+	ids := make([]int, 0, len(itemIds))
+	before := make(map[int]*models.ToDoItem, len(itemIds))
+	for _, id := range itemIds {
+		if _, ok := before[id]; ok {
+			continue
+		}
+
+		item, err := s.repo.GetById(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+		before[id] = item
+		ids = append(ids, id)
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	changes := make([]*models.AuditEntry, 0, len(ids))
+	for _, id := range ids {
+		after, err := s.repo.GetById(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+
+		changes = append(changes, itemChange(action, before[id], after))
+	}
+
+	return s.audit.record(ctx, userId, changes...)
 }
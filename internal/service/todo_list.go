@@ -3,36 +3,177 @@ package service
 import (
 	"Todo-app/internal/models"
 	"Todo-app/internal/repository"
+	"context"
 )
 
 type TodoListService struct {
-	repo repository.TodoList
+	repos    *repository.Repository
+	repo     repository.TodoList
+	itemRepo repository.TodoItem
+	audit    *AuditService
 }
 
-func NewTodoListService(repo repository.TodoList) *TodoListService {
-	return &TodoListService{repo: repo}
+func NewTodoListService(repos *repository.Repository) *TodoListService {
+	return &TodoListService{repos: repos, repo: repos.TodoList, itemRepo: repos.TodoItem, audit: auditOf(repos)}
 }
 
-func (s *TodoListService) Create(userId int, list *models.ToDoList) (int, error) {
-	return s.repo.Create(userId, list)
+func (s *TodoListService) Create(ctx context.Context, userId int, list *models.ToDoList) (int, error) {
+	var id int
+	err := inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		var err error
+		if id, err = tx.repo.Create(ctx, userId, list); err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, listChange(models.AuditCreate, id, nil, list.AuditFields()))
+	})
+
+	return id, err
 }
 
-func (s *TodoListService) GetAll(userId int) ([]*models.ToDoList, error) {
-	return s.repo.GetAll(userId)
+func (s *TodoListService) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error) {
+	return s.repo.GetAll(ctx, userId, page)
 }
 
-func (s *TodoListService) GetById(userId, listId int) (*models.ToDoList, error) {
-	return s.repo.GetById(userId, listId)
+func (s *TodoListService) GetById(ctx context.Context, userId, listId int) (*models.ToDoList, error) {
+	return s.repo.GetById(ctx, userId, listId)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+func (s *TodoListService) GetByIds(ctx context.Context, userId int, listIds []int) ([]*models.ToDoList, error) {
+	return s.repo.GetByIds(ctx, userId, listIds)
+}
+
+func (s *TodoListService) Delete(ctx context.Context, userId, listId int, version *int) error {
+	return inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		before, err := tx.repo.GetById(ctx, userId, listId)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.repo.Delete(ctx, userId, listId, version); err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, listChange(models.AuditDelete, listId, before.AuditFields(), nil))
+	})
 }
 
-func (s *TodoListService) Update(userId, listId int, input *models.UpdateListInput) error {
+func (s *TodoListService) Update(ctx context.Context, userId, listId int, input *models.UpdateListInput) error {
 	if err := input.Validate(); err != nil {
-		return err
+		return invalid(err)
+	}
+
+	return s.change(ctx, userId, listId, models.AuditUpdate, func(repo repository.TodoList) error {
+		return repo.Update(ctx, userId, listId, input)
+	})
+}
+
+func (s *TodoListService) Reorder(ctx context.Context, userId, listId int, input *models.PositionInput) error {
+	if err := input.Validate(); err != nil {
+		return invalid(err)
+	}
+
+	return s.change(ctx, userId, listId, models.AuditReorder, func(repo repository.TodoList) error {
+		return repo.Reorder(ctx, userId, listId, input)
+	})
+}
+
+func (s *TodoListService) Duplicate(ctx context.Context, userId, listId int, title string) (*models.ToDoList, error) {
+	var list *models.ToDoList
+	err := inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		var err error
+		if list, err = tx.repo.Duplicate(ctx, userId, listId, title); err != nil {
+			return err
+		}
+
+		items, err := allItems(ctx, tx.itemRepo, userId, list.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, userId, listCreated(list, items)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// allItems reads every item of a list, page by page.
+func allItems(ctx context.Context, repo repository.TodoItem, userId, listId int) ([]*models.ToDoItem, error) {
+	var items []*models.ToDoItem
+	page := &models.PageRequest{Limit: models.MaxPageSize}
+
+	for {
+		batch, info, err := repo.GetAll(ctx, userId, listId, page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, batch...)
+
+		if info.Next == nil {
+			return items, nil
+		}
+		page.After = info.Next
 	}
+}
+
+func (s *TodoListService) Restore(ctx context.Context, userId, listId int) error {
+	return inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		if err := tx.repo.Restore(ctx, userId, listId); err != nil {
+			return err
+		}
+
+		before, after := deletedFlag(true, false)
+		return tx.audit.record(ctx, userId, listChange(models.AuditRestore, listId, before, after))
+	})
+}
+
+func (s *TodoListService) Archive(ctx context.Context, userId, listId int) error {
+	return inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		if err := tx.repo.Archive(ctx, userId, listId); err != nil {
+			return err
+		}
+
+		before, after := archivedFlag(false, true)
+		return tx.audit.record(ctx, userId, listChange(models.AuditArchive, listId, before, after))
+	})
+}
+
+func (s *TodoListService) Unarchive(ctx context.Context, userId, listId int) error {
+	return inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		if err := tx.repo.Unarchive(ctx, userId, listId); err != nil {
+			return err
+		}
+
+		before, after := archivedFlag(true, false)
+		return tx.audit.record(ctx, userId, listChange(models.AuditUnarchive, listId, before, after))
+	})
+}
+
+func (s *TodoListService) GetArchived(ctx context.Context, userId int, page *models.PageRequest) ([]*models.ToDoList, *models.PageInfo, error) {
+	return s.repo.GetArchived(ctx, userId, page)
+}
+
+// change runs fn in a transaction and records the difference it made to the
+// list.
+func (s *TodoListService) change(ctx context.Context, userId, listId int, action string, fn func(repo repository.TodoList) error) error {
+	return inTx(ctx, s.repos, NewTodoListService, func(ctx context.Context, tx *TodoListService) error {
+		before, err := tx.repo.GetById(ctx, userId, listId)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tx.repo); err != nil {
+			return err
+		}
+
+		after, err := tx.repo.GetById(ctx, userId, listId)
+		if err != nil {
+			return err
+		}
 
-	return s.repo.Update(userId, listId, input)
+		return tx.audit.record(ctx, userId, listChange(action, listId, before.AuditFields(), after.AuditFields()))
+	})
 }
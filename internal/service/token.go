@@ -0,0 +1,48 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+)
+
+type TokenService struct {
+	repo repository.Token
+}
+
+func NewTokenService(repo repository.Token) *TokenService {
+	return &TokenService{repo: repo}
+}
+
+// Create issues a new token to the user and returns it with its secret,
+// which isn't stored and can't be read again.
+func (s *TokenService) Create(ctx context.Context, userId int, input *models.TokenInput) (*models.APIToken, string, error) {
+	if err := input.Validate(); err != nil {
+		return nil, "", invalid(err)
+	}
+
+	secret, err := models.NewTokenSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &models.APIToken{UserID: userId, Name: input.Name}
+	if err := s.repo.Create(ctx, token, models.HashToken(secret)); err != nil {
+		return nil, "", err
+	}
+
+	return token, secret, nil
+}
+
+func (s *TokenService) GetAll(ctx context.Context, userId int) ([]*models.APIToken, error) {
+	return s.repo.GetAll(ctx, userId)
+}
+
+// Authenticate returns the owner of the token with the given secret.
+func (s *TokenService) Authenticate(ctx context.Context, secret string) (*models.User, error) {
+	return s.repo.FindUser(ctx, models.HashToken(secret))
+}
+
+func (s *TokenService) Revoke(ctx context.Context, userId, tokenId int) error {
+	return s.repo.Delete(ctx, userId, tokenId)
+}
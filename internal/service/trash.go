@@ -0,0 +1,30 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"time"
+)
+
+type TrashService struct {
+	repo repository.Trash
+}
+
+func NewTrashService(repo repository.Trash) *TrashService {
+	return &TrashService{repo: repo}
+}
+
+func (s *TrashService) GetLists(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedList, *models.PageInfo, error) {
+	return s.repo.GetLists(ctx, userId, page)
+}
+
+func (s *TrashService) GetItems(ctx context.Context, userId int, page *models.PageRequest) ([]*models.TrashedItem, *models.PageInfo, error) {
+	return s.repo.GetItems(ctx, userId, page)
+}
+
+// Purge permanently deletes lists and items that have been in the trash for
+// longer than retention and returns the number of removed rows.
+func (s *TrashService) Purge(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,464 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrUndoConflict is returned when a step can't be undone or redone because
+// the affected list or item was changed by a later action. The step is
+// dropped, so that it doesn't block the steps before it.
+var ErrUndoConflict = &Error{Kind: KindConflict, Code: "undo_conflict", Message: "the change was modified afterwards and can't be reverted, so it was dropped"}
+
+type UndoService struct {
+	repos  *repository.Repository
+	ops    repository.Operation
+	lists  repository.TodoList
+	items  repository.TodoItem
+	audit  *AuditService
+	window time.Duration
+}
+
+func NewUndoService(repos *repository.Repository, window time.Duration) *UndoService {
+	return &UndoService{repos: repos, ops: repos.Operation, lists: repos.TodoList, items: repos.TodoItem, audit: auditOf(repos), window: window}
+}
+
+// withRepos returns a copy of the service that uses repos.
+func (s *UndoService) withRepos(repos *repository.Repository) *UndoService {
+	return NewUndoService(repos, s.window)
+}
+
+// Undo reverts the user's latest steps, newest first. It stops at the first
+// step that conflicts with a later change, which it drops, and returns the
+// steps it reverted.
+func (s *UndoService) Undo(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error) {
+	return s.run(ctx, userId, input, true)
+}
+
+// Redo re-applies the user's most recently undone steps.
+func (s *UndoService) Redo(ctx context.Context, userId int, input *models.UndoInput) ([]*models.Operation, error) {
+	return s.run(ctx, userId, input, false)
+}
+
+func (s *UndoService) run(ctx context.Context, userId int, input *models.UndoInput, undo bool) ([]*models.Operation, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	count := input.Count
+	if count == 0 {
+		count = 1
+	}
+
+	since := time.Now().Add(-s.window)
+	done := make([]*models.Operation, 0, count)
+
+	for len(done) < count {
+		// a step that fails is rolled back together with its claim; one that
+		// conflicts with a later change is dropped afterwards, on its own
+		var op *models.Operation
+		err := inTx(ctx, s.repos, s.withRepos, func(ctx context.Context, tx *UndoService) error {
+			var err error
+			if op, err = tx.claim(ctx, userId, since, undo); err != nil {
+				return err
+			}
+
+			return tx.apply(ctx, userId, op, undo)
+		})
+		if op == nil && errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			if op != nil && (errors.Is(err, sql.ErrNoRows) || errors.Is(err, repository.ErrConflict) || errors.Is(err, ErrUndoConflict)) {
+				if err := s.ops.Discard(ctx, userId, op.ID); err != nil {
+					return done, err
+				}
+				err = ErrUndoConflict
+			}
+			return done, err
+		}
+
+		done = append(done, op)
+	}
+
+	return done, nil
+}
+
+// claim takes the user's latest step to undo or redo.
+func (s *UndoService) claim(ctx context.Context, userId int, since time.Time, undo bool) (*models.Operation, error) {
+	if undo {
+		return s.ops.ClaimUndo(ctx, userId, since)
+	}
+
+	return s.ops.ClaimRedo(ctx, userId, since)
+}
+
+func (s *UndoService) apply(ctx context.Context, userId int, op *models.Operation, undo bool) error {
+	entries := make([]*models.AuditEntry, len(op.Entries))
+	copy(entries, op.Entries)
+	if undo {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	for _, entry := range entries {
+		if err := s.check(ctx, userId, entry, undo); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		change, err := s.revert(ctx, userId, entry, undo)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.audit.write(ctx, userId, []*models.AuditEntry{change}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check makes sure the entity still looks the way the step left it, so
+// reverting it can't clobber somebody else's later change.
+func (s *UndoService) check(ctx context.Context, userId int, entry *models.AuditEntry, undo bool) error {
+	switch revertAction(entry.Action, undo) {
+	case models.AuditRestore, models.AuditArchive, models.AuditUnarchive:
+		// the repository refuses these when the row isn't in the expected state
+		return nil
+	}
+
+	var current map[string]interface{}
+	switch entry.Entity {
+	case models.AuditEntityList:
+		list, err := s.lists.GetById(ctx, userId, entry.EntityID)
+		if err != nil {
+			return err
+		}
+		current = list.AuditFields()
+	default:
+		item, err := s.items.GetById(ctx, userId, entry.EntityID)
+		if err != nil {
+			return err
+		}
+		current = item.AuditFields()
+	}
+
+	current, err := normalize(current)
+	if err != nil {
+		return err
+	}
+
+	expected, _ := sides(entry, undo)
+	for field, want := range expected {
+		if got, ok := current[field]; ok && !reflect.DeepEqual(got, want) {
+			return ErrUndoConflict
+		}
+	}
+
+	return nil
+}
+
+func (s *UndoService) revert(ctx context.Context, userId int, entry *models.AuditEntry, undo bool) (*models.AuditEntry, error) {
+	action := revertAction(entry.Action, undo)
+	_, target := sides(entry, undo)
+
+	if entry.Entity == models.AuditEntityList {
+		return s.revertList(ctx, userId, entry.EntityID, action, target)
+	}
+
+	return s.revertItem(ctx, userId, entry.EntityID, action, target)
+}
+
+func (s *UndoService) revertList(ctx context.Context, userId, listId int, action string, target map[string]interface{}) (*models.AuditEntry, error) {
+	switch action {
+	case models.AuditDelete:
+		before, err := s.lists.GetById(ctx, userId, listId)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.lists.Delete(ctx, userId, listId, nil); err != nil {
+			return nil, err
+		}
+		return listChange(action, listId, before.AuditFields(), nil), nil
+
+	case models.AuditRestore:
+		if err := s.lists.Restore(ctx, userId, listId); err != nil {
+			return nil, err
+		}
+		before, after := deletedFlag(true, false)
+		return listChange(action, listId, before, after), nil
+
+	case models.AuditArchive:
+		if err := s.lists.Archive(ctx, userId, listId); err != nil {
+			return nil, err
+		}
+		before, after := archivedFlag(false, true)
+		return listChange(action, listId, before, after), nil
+
+	case models.AuditUnarchive:
+		if err := s.lists.Unarchive(ctx, userId, listId); err != nil {
+			return nil, err
+		}
+		before, after := archivedFlag(true, false)
+		return listChange(action, listId, before, after), nil
+	}
+
+	before, err := s.lists.GetById(ctx, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &models.UpdateListInput{}
+	for field, value := range target {
+		v, ok := value.(string)
+		if !ok {
+			return nil, ErrUndoConflict
+		}
+
+		switch field {
+		case "title":
+			input.Title = &v
+		case "description":
+			input.Description = &v
+		case "position":
+			if err := s.lists.Place(ctx, userId, listId, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if input.Validate() == nil {
+		if err := s.lists.Update(ctx, userId, listId, input); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := s.lists.GetById(ctx, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	return listChange(models.AuditUpdate, listId, before.AuditFields(), after.AuditFields()), nil
+}
+
+func (s *UndoService) revertItem(ctx context.Context, userId, itemId int, action string, target map[string]interface{}) (*models.AuditEntry, error) {
+	switch action {
+	case models.AuditDelete:
+		before, err := s.items.GetById(ctx, userId, itemId)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.items.Delete(ctx, userId, itemId, nil); err != nil {
+			return nil, err
+		}
+		return itemChange(action, before, nil), nil
+
+	case models.AuditRestore:
+		if err := s.items.Restore(ctx, userId, itemId); err != nil {
+			return nil, err
+		}
+		after, err := s.items.GetById(ctx, userId, itemId)
+		if err != nil {
+			return nil, err
+		}
+		change := itemChange(action, nil, after)
+		change.Changes = models.Diff(deletedFlag(true, false))
+		return change, nil
+	}
+
+	before, err := s.items.GetById(ctx, userId, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	title, description, done, labels, priority := before.Title, before.Description, before.Done, before.Labels, before.Priority
+	input := &models.UpdateItemInput{
+		Title:       &title,
+		Description: &description,
+		Done:        &done,
+		Due:         before.Due,
+		Labels:      &labels,
+		Priority:    &priority,
+		AssigneeID:  before.AssigneeID,
+	}
+	fieldsChanged := false
+	listId, position := before.ListID, before.Position
+	placed := false
+
+	for field, value := range target {
+		ok := true
+		switch field {
+		case "title":
+			title, ok = value.(string)
+			fieldsChanged = true
+		case "description":
+			description, ok = value.(string)
+			fieldsChanged = true
+		case "done":
+			done, ok = value.(bool)
+			fieldsChanged = true
+		case "due":
+			input.Due, ok = parseDue(value)
+			fieldsChanged = true
+		case "labels":
+			labels, ok = parseLabels(value)
+			fieldsChanged = true
+		case "priority":
+			var p float64
+			p, ok = value.(float64)
+			priority, fieldsChanged = int(p), true
+		case "assignee_id":
+			input.AssigneeID, ok = parseAssignee(value)
+			fieldsChanged = true
+		case "list_id":
+			var id float64
+			id, ok = value.(float64)
+			listId, placed = int(id), true
+		case "position":
+			position, ok = value.(string)
+			placed = true
+		}
+
+		if !ok {
+			return nil, ErrUndoConflict
+		}
+	}
+
+	if fieldsChanged {
+		input.ClearDue, input.ClearAssignee = input.Due == nil, input.AssigneeID == nil
+		if err := s.items.Update(ctx, userId, itemId, input); err != nil {
+			return nil, err
+		}
+	}
+
+	if placed {
+		if err := s.items.Place(ctx, userId, itemId, listId, position); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := s.items.GetById(ctx, userId, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return itemChange(models.AuditUpdate, before, after), nil
+}
+
+// revertAction returns the action that undoes (or redoes) a recorded one.
+// Field updates, reorders and moves are reverted by writing the recorded
+// field values back, which revertAction reports as an update.
+func revertAction(action string, undo bool) string {
+	if !undo {
+		switch action {
+		case models.AuditCreate:
+			// undoing a create moved the row to the trash
+			return models.AuditRestore
+		case models.AuditDelete, models.AuditRestore, models.AuditArchive, models.AuditUnarchive:
+			return action
+		}
+		return models.AuditUpdate
+	}
+
+	switch action {
+	case models.AuditCreate, models.AuditRestore:
+		return models.AuditDelete
+	case models.AuditDelete:
+		return models.AuditRestore
+	case models.AuditArchive:
+		return models.AuditUnarchive
+	case models.AuditUnarchive:
+		return models.AuditArchive
+	}
+
+	return models.AuditUpdate
+}
+
+// sides returns the field values the entity is expected to have now and the
+// values it should be given.
+func sides(entry *models.AuditEntry, undo bool) (expected, target map[string]interface{}) {
+	expected = make(map[string]interface{}, len(entry.Changes))
+	target = make(map[string]interface{}, len(entry.Changes))
+
+	for field, change := range entry.Changes {
+		if undo {
+			expected[field], target[field] = change.After, change.Before
+		} else {
+			expected[field], target[field] = change.Before, change.After
+		}
+	}
+
+	return expected, target
+}
+
+// normalize converts a snapshot to the types it has after a trip through the
+// audit log's JSON column.
+func normalize(fields map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]interface{}
+	return out, json.Unmarshal(b, &out)
+}
+
+func parseDue(value interface{}) (*time.Time, bool) {
+	if value == nil {
+		return nil, true
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+
+	due, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, false
+	}
+
+	return &due, true
+}
+
+func parseAssignee(value interface{}) (*int, bool) {
+	if value == nil {
+		return nil, true
+	}
+
+	id, ok := value.(float64)
+	if !ok {
+		return nil, false
+	}
+
+	assignee := int(id)
+	return &assignee, true
+}
+
+func parseLabels(value interface{}) ([]string, bool) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	labels := make([]string, 0, len(values))
+	for _, v := range values {
+		label, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, true
+}
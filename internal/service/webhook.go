@@ -0,0 +1,333 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// ErrAdminRequired is returned when someone who isn't an admin registers a
+// webhook for the whole workspace.
+var ErrAdminRequired = &Error{Kind: KindForbidden, Code: "admin_required", Message: "only admins can register webhooks for the whole workspace"}
+
+const (
+	// webhookBatch is how many deliveries one round of the worker sends.
+	webhookBatch = 20
+	// webhookTimeout bounds one attempt, webhookLease how long a claimed
+	// delivery waits before another worker may try it.
+	webhookTimeout = 10 * time.Second
+	webhookLease   = time.Minute
+	// A failed attempt is retried after webhookBackoff, doubling with every
+	// further attempt up to webhookMaxBackoff, until webhookMaxAttempts.
+	webhookBackoff     = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookMaxAttempts = 10
+	// webhookDisableAfter is how many attempts in a row may fail before the
+	// webhook is disabled.
+	webhookDisableAfter = 15
+)
+
+type WebhookService struct {
+	repo      repository.Webhook
+	lists     repository.TodoList
+	users     repository.Authorization
+	client    *http.Client
+	allow     func(netip.Addr) bool
+	retention time.Duration
+}
+
+// NewWebhookService keeps the history of finished deliveries for retention.
+func NewWebhookService(repo repository.Webhook, lists repository.TodoList, users repository.Authorization, retention time.Duration) *WebhookService {
+	return &WebhookService{
+		repo:      repo,
+		lists:     lists,
+		users:     users,
+		client:    newWebhookClient(models.WebhookAddressAllowed),
+		allow:     models.WebhookAddressAllowed,
+		retention: retention,
+	}
+}
+
+// newWebhookClient returns the client deliveries are sent with. It connects
+// only to the addresses allow accepts and checks them after a host name was
+// resolved, so a name can't be pointed at an internal address once the
+// webhook is registered.
+func newWebhookClient(allow func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(addr.Addr()) {
+				return fmt.Errorf("webhook address %s isn't allowed", addr.Addr())
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		// no proxy, so that the address checked is the receiver's
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+		// a redirect is an answer, not a delivery
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkURL resolves the host of a webhook URL and refuses it unless every
+// address it resolves to is allowed.
+func (s *WebhookService) checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return invalid(validation.Errors{"url": err})
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return invalid(validation.Errors{"url": errors.New("the host can't be resolved")})
+	}
+
+	for _, addr := range addrs {
+		if !s.allow(addr) {
+			return invalid(validation.Errors{"url": models.ErrWebhookAddress})
+		}
+	}
+
+	return nil
+}
+
+// Create registers a webhook for a list the user can access or, for admins,
+// for the whole workspace. The webhook is returned with its secret.
+func (s *WebhookService) Create(ctx context.Context, userId int, input *models.WebhookInput) (*models.Webhook, error) {
+	if err := input.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	if err := s.checkURL(ctx, input.URL); err != nil {
+		return nil, err
+	}
+
+	if input.ListID != nil {
+		if _, err := s.lists.GetById(ctx, userId, *input.ListID); err != nil {
+			return nil, err
+		}
+	} else {
+		u, err := s.users.Find(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		if !u.IsAdmin {
+			return nil, ErrAdminRequired
+		}
+	}
+
+	secret, err := models.NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &models.Webhook{UserID: userId, ListID: input.ListID, URL: input.URL, Events: input.Events, Secret: secret}
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+func (s *WebhookService) GetAll(ctx context.Context, userId int, page *models.PageRequest) ([]*models.Webhook, *models.PageInfo, error) {
+	return s.repo.GetAll(ctx, userId, page)
+}
+
+func (s *WebhookService) GetById(ctx context.Context, userId, hookId int) (*models.Webhook, error) {
+	return s.repo.GetById(ctx, userId, hookId)
+}
+
+func (s *WebhookService) Update(ctx context.Context, userId, hookId int, input *models.UpdateWebhookInput) error {
+	if err := input.Validate(); err != nil {
+		return invalid(err)
+	}
+
+	if input.URL != nil {
+		if err := s.checkURL(ctx, *input.URL); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Update(ctx, userId, hookId, input)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userId, hookId int) error {
+	return s.repo.Delete(ctx, userId, hookId)
+}
+
+// GetDeliveries returns the delivery history of one of the user's webhooks,
+// newest first, optionally only the deliveries with the given status.
+func (s *WebhookService) GetDeliveries(ctx context.Context, userId, hookId int, status string, page *models.PageRequest) ([]*models.WebhookDelivery, *models.PageInfo, error) {
+	if err := validation.Validate(status, validation.In(models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed)); err != nil {
+		return nil, nil, invalid(validation.Errors{"status": err})
+	}
+
+	if _, err := s.repo.GetById(ctx, userId, hookId); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repo.GetDeliveries(ctx, hookId, status, page)
+}
+
+// Redeliver sends the event of an earlier delivery again, as a new delivery.
+func (s *WebhookService) Redeliver(ctx context.Context, userId, hookId int, deliveryId int64) (*models.WebhookDelivery, error) {
+	if _, err := s.repo.GetById(ctx, userId, hookId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Redeliver(ctx, hookId, deliveryId)
+}
+
+// Enqueue queues a domain event for the webhooks subscribed to it. It is the
+// outbox subscriber of the webhooks; events that only follow from another one
+// have no webhook event of their own and are skipped.
+func (s *WebhookService) Enqueue(ctx context.Context, event *models.DomainEvent) error {
+	name := models.WebhookEvent(event.Type)
+	if name == "" {
+		return nil
+	}
+
+	_, err := s.repo.Enqueue(ctx, event.AuditID, event.ListID, name)
+	return err
+}
+
+// Deliver sends a batch of due deliveries and returns how many it sent. The
+// outcome of each is recorded; failed attempts are retried later. dbTimeout
+// bounds each database call on its own, since the attempts are bounded by
+// webhookTimeout and a receiver that hangs mustn't keep its outcome from being
+// recorded.
+func (s *WebhookService) Deliver(ctx context.Context, dbTimeout time.Duration) (int, error) {
+	claimCtx, cancel := DBContext(ctx, dbTimeout)
+	jobs, err := s.repo.Claim(claimCtx, webhookBatch, webhookLease)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	errs := make([]error, len(jobs))
+	var wg sync.WaitGroup
+	for n, job := range jobs {
+		wg.Add(1)
+		go func(n int, job *models.WebhookJob) {
+			defer wg.Done()
+			errs[n] = s.send(ctx, job, dbTimeout)
+		}(n, job)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return len(jobs), err
+		}
+	}
+
+	return len(jobs), nil
+}
+
+// send makes one attempt at a delivery and records its outcome. Only failures
+// to record the outcome are returned.
+func (s *WebhookService) send(ctx context.Context, job *models.WebhookJob, dbTimeout time.Duration) error {
+	responseStatus, err := s.post(ctx, job)
+
+	// the outcome is recorded even if the attempt used up ctx
+	ctx, cancel := DBContext(context.WithoutCancel(ctx), dbTimeout)
+	defer cancel()
+
+	if err != nil {
+		return s.fail(ctx, job.Delivery, responseStatus, err.Error())
+	}
+
+	return s.repo.Succeed(ctx, job.Delivery.ID, *responseStatus)
+}
+
+// post sends a delivery to its webhook within webhookTimeout. It returns the
+// status of the response, if there was one, and an error unless the receiver
+// accepted the delivery.
+func (s *WebhookService) post(ctx context.Context, job *models.WebhookJob) (*int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	d := job.Delivery
+	body, err := json.Marshal(&models.WebhookPayload{ID: d.ID, WebhookID: d.WebhookID, Event: d.Event, Change: job.Change})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Todo-app-Webhooks/1")
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Signature", models.SignWebhook(job.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, errors.New(resp.Status)
+	}
+
+	return &resp.StatusCode, nil
+}
+
+func (s *WebhookService) fail(ctx context.Context, d *models.WebhookDelivery, responseStatus *int, reason string) error {
+	var retryAt *time.Time
+	if d.Attempts < webhookMaxAttempts {
+		at := time.Now().Add(webhookRetryDelay(d.Attempts))
+		retryAt = &at
+	}
+
+	return s.repo.Fail(ctx, d.ID, responseStatus, reason, retryAt, webhookDisableAfter)
+}
+
+// webhookRetryDelay is how long a delivery waits after its failed attempt.
+func webhookRetryDelay(attempt int) time.Duration {
+	if attempt > 20 {
+		return webhookMaxBackoff
+	}
+
+	return min(webhookBackoff<<(attempt-1), webhookMaxBackoff)
+}
+
+// Purge removes the finished deliveries older than the retention period.
+func (s *WebhookService) Purge(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-s.retention))
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"Todo-app/internal/models"
+	"Todo-app/internal/repository"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWebhookRepo hands out the queued jobs and records outcomes the way
+// WebhookPostgres does: a claim counts an attempt, a failure counts against
+// the webhook and disables it after disableAfter failures in a row.
+type fakeWebhookRepo struct {
+	repository.Webhook
+
+	mu       sync.Mutex
+	queued   []*models.WebhookJob
+	outcomes []*webhookOutcome
+	failures int
+	disabled bool
+}
+
+type webhookOutcome struct {
+	deliveryId     int64
+	succeeded      bool
+	responseStatus *int
+	reason         string
+	retryAt        *time.Time
+	disableAfter   int
+}
+
+func (r *fakeWebhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := r.queued[:min(limit, len(r.queued))]
+	r.queued = r.queued[len(jobs):]
+	for _, job := range jobs {
+		job.Delivery.Attempts++
+	}
+
+	return jobs, nil
+}
+
+func (r *fakeWebhookRepo) Succeed(ctx context.Context, deliveryId int64, responseStatus int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = 0
+	r.outcomes = append(r.outcomes, &webhookOutcome{deliveryId: deliveryId, succeeded: true, responseStatus: &responseStatus})
+	return nil
+}
+
+func (r *fakeWebhookRepo) Fail(ctx context.Context, deliveryId int64, responseStatus *int, reason string, retryAt *time.Time, disableAfter int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures++
+	if r.failures >= disableAfter {
+		r.disabled = true
+	}
+	r.outcomes = append(r.outcomes, &webhookOutcome{deliveryId: deliveryId, responseStatus: responseStatus, reason: reason,
+		retryAt: retryAt, disableAfter: disableAfter})
+	return nil
+}
+
+// deliver queues a delivery to url that was attempted attempts times before
+// and sends it.
+func (r *fakeWebhookRepo) deliver(t *testing.T, s *WebhookService, url string, attempts int) *webhookOutcome {
+	t.Helper()
+
+	r.mu.Lock()
+	id := int64(len(r.outcomes) + 1)
+	r.queued = append(r.queued, &models.WebhookJob{
+		Delivery: &models.WebhookDelivery{ID: id, WebhookID: 3, AuditID: 40 + id, Event: "item.update", Attempts: attempts},
+		URL:      url,
+		Secret:   "whsec_test",
+		Change:   &models.AuditEntry{ID: 40 + id, Action: models.AuditUpdate, Entity: models.AuditEntityItem, EntityID: 9, ListID: 2},
+	})
+	r.mu.Unlock()
+
+	if n, err := s.Deliver(context.Background(), time.Second); err != nil || n != 1 {
+		t.Fatalf("Deliver() = %d, %v, want 1 delivery", n, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outcomes[len(r.outcomes)-1]
+}
+
+func newTestWebhookService(repo repository.Webhook) *WebhookService {
+	// the test servers listen on loopback addresses
+	allow := func(netip.Addr) bool { return true }
+	return &WebhookService{repo: repo, client: newWebhookClient(allow), allow: allow}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	var header http.Header
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	before := time.Now().Unix()
+	outcome := repo.deliver(t, newTestWebhookService(repo), receiver.URL, 0)
+	if !outcome.succeeded || *outcome.responseStatus != http.StatusOK {
+		t.Fatalf("delivery outcome = %+v, want succeeded with 200", outcome)
+	}
+
+	if got := header.Get("X-Webhook-Event"); got != "item.update" {
+		t.Errorf("X-Webhook-Event = %q", got)
+	}
+	if got := header.Get("X-Webhook-Delivery"); got != "1" {
+		t.Errorf("X-Webhook-Delivery = %q", got)
+	}
+
+	m := regexp.MustCompile(`^t=(\d+),v1=([0-9a-f]{64})$`).FindStringSubmatch(header.Get("X-Webhook-Signature"))
+	if m == nil {
+		t.Fatalf("X-Webhook-Signature = %q, want t=<unix>,v1=<hex>", header.Get("X-Webhook-Signature"))
+	}
+
+	at, _ := strconv.ParseInt(m[1], 10, 64)
+	if at < before || at > time.Now().Unix() {
+		t.Errorf("signature time %d isn't the time of sending", at)
+	}
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(m[1] + "."))
+	mac.Write(body)
+	if want := hex.EncodeToString(mac.Sum(nil)); !hmac.Equal([]byte(m[2]), []byte(want)) {
+		t.Errorf("v1 = %s, want %s", m[2], want)
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != 1 || payload.WebhookID != 3 || payload.Event != "item.update" || payload.Change.ID != 41 {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	s := newTestWebhookService(repo)
+
+	for attempts := 0; attempts < webhookMaxAttempts-1; attempts++ {
+		sent := time.Now()
+		outcome := repo.deliver(t, s, receiver.URL, attempts)
+
+		if outcome.succeeded || outcome.responseStatus == nil || *outcome.responseStatus != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: outcome = %+v, want a failure with 503", attempts+1, outcome)
+		}
+		if outcome.retryAt == nil {
+			t.Fatalf("attempt %d isn't retried", attempts+1)
+		}
+
+		backoff := webhookBackoff << attempts
+		if wait := outcome.retryAt.Sub(sent); wait < backoff || wait > backoff+time.Second {
+			t.Errorf("attempt %d is retried after %v, want %v", attempts+1, wait, backoff)
+		}
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, webhookMaxBackoff},
+		{20, webhookMaxBackoff},
+		{21, webhookMaxBackoff},
+		{64, webhookMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := webhookRetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("webhookRetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	repo := &fakeWebhookRepo{}
+	s := newTestWebhookService(repo)
+	s.client.Timeout = 50 * time.Millisecond
+
+	sent := time.Now()
+	outcome := repo.deliver(t, s, receiver.URL, 0)
+	if outcome.succeeded || outcome.responseStatus != nil || outcome.reason == "" {
+		t.Fatalf("outcome = %+v, want a failure without a response", outcome)
+	}
+	if outcome.retryAt == nil || outcome.retryAt.Sub(sent) < webhookBackoff {
+		t.Errorf("a timed out attempt is retried at %v, want after %v", outcome.retryAt, webhookBackoff)
+	}
+}
+
+func TestWebhookOutlastsDBTimeout(t *testing.T) {
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	repo := &fakeWebhookRepo{queued: []*models.WebhookJob{{
+		Delivery: &models.WebhookDelivery{ID: 1, WebhookID: 3, AuditID: 41, Event: "item.update"},
+		URL:      receiver.URL,
+		Change:   &models.AuditEntry{ID: 41},
+	}}}
+	s := newTestWebhookService(repo)
+	s.client.Timeout = 200 * time.Millisecond
+
+	// the attempt takes longer than a database call may, yet its failure has
+	// to be recorded so that it backs off and counts against the webhook
+	if n, err := s.Deliver(context.Background(), 50*time.Millisecond); err != nil || n != 1 {
+		t.Fatalf("Deliver() = %d, %v, want 1 delivery", n, err)
+	}
+	if len(repo.outcomes) != 1 || repo.outcomes[0].succeeded || repo.outcomes[0].retryAt == nil || repo.failures != 1 {
+		t.Errorf("outcomes = %+v, want one failure to retry", repo.outcomes)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	outcome := repo.deliver(t, newTestWebhookService(repo), receiver.URL, webhookMaxAttempts-1)
+
+	// without a retry time the delivery is recorded as failed for good
+	if outcome.succeeded || outcome.retryAt != nil {
+		t.Errorf("attempt %d: outcome = %+v, want a failure without a retry", webhookMaxAttempts, outcome)
+	}
+}
+
+func TestWebhookDisabledAfterFailures(t *testing.T) {
+	var fail bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	s := newTestWebhookService(repo)
+
+	// a success in between starts the count again
+	fail = true
+	for i := 0; i < webhookDisableAfter-1; i++ {
+		repo.deliver(t, s, receiver.URL, 0)
+	}
+	fail = false
+	repo.deliver(t, s, receiver.URL, 0)
+	fail = true
+
+	for i := 0; i < webhookDisableAfter; i++ {
+		if repo.disabled {
+			t.Fatalf("the webhook is disabled after %d failures in a row", i)
+		}
+
+		if outcome := repo.deliver(t, s, receiver.URL, 0); outcome.disableAfter != webhookDisableAfter {
+			t.Fatalf("disableAfter = %d, want %d", outcome.disableAfter, webhookDisableAfter)
+		}
+	}
+
+	if !repo.disabled {
+		t.Errorf("the webhook isn't disabled after %d failures in a row", webhookDisableAfter)
+	}
+}
+
+func TestWebhookRedirectFails(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	outcome := repo.deliver(t, newTestWebhookService(repo), receiver.URL, 0)
+
+	if outcome.succeeded || outcome.responseStatus == nil || *outcome.responseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("outcome = %+v, want a failure with 307", outcome)
+	}
+	if outcome.retryAt == nil {
+		t.Error("a redirected attempt isn't retried")
+	}
+	if followed {
+		t.Error("the redirect was followed")
+	}
+}
+
+func TestWebhookInternalAddress(t *testing.T) {
+	var reached bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer receiver.Close()
+
+	repo := &fakeWebhookRepo{}
+	s := newTestWebhookService(repo)
+	s.allow = models.WebhookAddressAllowed
+	s.client = newWebhookClient(s.allow)
+
+	outcome := repo.deliver(t, s, receiver.URL, 0)
+	if outcome.succeeded || reached {
+		t.Errorf("a delivery to %s reached the receiver", receiver.URL)
+	}
+
+	if err := s.checkURL(context.Background(), receiver.URL); err == nil {
+		t.Errorf("checkURL(%s) accepts a loopback address", receiver.URL)
+	}
+}
@@ -0,0 +1,9 @@
+DROP INDEX users_lists_position_idx;
+
+DROP INDEX lists_items_position_idx;
+
+ALTER TABLE users_lists
+    DROP COLUMN position;
+
+ALTER TABLE lists_items
+    DROP COLUMN position;
@@ -0,0 +1,25 @@
+ALTER TABLE lists_items
+    ADD COLUMN position text COLLATE "C";
+
+ALTER TABLE users_lists
+    ADD COLUMN position text COLLATE "C";
+
+UPDATE lists_items li
+SET position = 'j' || lpad(r.n::text, 10, '0')
+FROM (SELECT id, row_number() OVER (PARTITION BY list_id ORDER BY item_id) AS n FROM lists_items) r
+WHERE li.id = r.id;
+
+UPDATE users_lists ul
+SET position = 'j' || lpad(r.n::text, 10, '0')
+FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY list_id) AS n FROM users_lists) r
+WHERE ul.id = r.id;
+
+ALTER TABLE lists_items
+    ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE users_lists
+    ALTER COLUMN position SET NOT NULL;
+
+CREATE UNIQUE INDEX lists_items_position_idx ON lists_items (list_id, position);
+
+CREATE UNIQUE INDEX users_lists_position_idx ON users_lists (user_id, position);
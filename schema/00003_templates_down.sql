@@ -0,0 +1,7 @@
+DROP TABLE template_items;
+
+DROP TABLE list_templates;
+
+ALTER TABLE todo_items
+    DROP COLUMN labels,
+    DROP COLUMN due;
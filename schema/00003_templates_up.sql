@@ -0,0 +1,25 @@
+ALTER TABLE todo_items
+    ADD COLUMN due    timestamptz,
+    ADD COLUMN labels text[] not null default '{}';
+
+CREATE TABLE list_templates
+(
+    id          serial                                      not null unique,
+    user_id     int references users (id) on delete cascade not null,
+    title       varchar(255)                                not null,
+    description varchar(255)                                not null default '',
+    shared      boolean                                     not null default false
+);
+
+CREATE TABLE template_items
+(
+    id          serial                                               not null unique,
+    template_id int references list_templates (id) on delete cascade not null,
+    title       varchar(255)                                         not null,
+    description varchar(255)                                         not null default '',
+    due_offset  bigint,
+    labels      text[]                                               not null default '{}',
+    position    text COLLATE "C"                                     not null
+);
+
+CREATE INDEX list_templates_shared_idx ON list_templates (shared) WHERE shared;
@@ -0,0 +1,8 @@
+DROP TRIGGER audit_log_append_only ON audit_log;
+
+DROP FUNCTION audit_log_append_only();
+
+DROP TABLE audit_log;
+
+ALTER TABLE users
+    DROP COLUMN is_admin;
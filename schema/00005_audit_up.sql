@@ -0,0 +1,35 @@
+ALTER TABLE users
+    ADD COLUMN is_admin boolean not null default false;
+
+CREATE TABLE audit_log
+(
+    id         bigserial   not null unique,
+    actor_id   int         not null,
+    action     varchar(32) not null,
+    entity     varchar(16) not null,
+    entity_id  int         not null,
+    list_id    int         not null,
+    changes    jsonb       not null default '{}',
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity, entity_id, id);
+
+CREATE INDEX audit_log_list_idx ON audit_log (list_id, id);
+
+CREATE INDEX audit_log_actor_idx ON audit_log (actor_id, created_at);
+
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,10 @@
+CREATE TABLE user_operations
+(
+    id         bigserial                                   not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    audit_ids  bigint[]                                    not null,
+    created_at timestamptz                                 not null default now(),
+    undone_at  timestamptz
+);
+
+CREATE INDEX user_operations_user_idx ON user_operations (user_id, id);
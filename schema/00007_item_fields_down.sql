@@ -0,0 +1,9 @@
+DROP INDEX todo_items_labels_idx;
+
+DROP INDEX todo_items_assignee_id_idx;
+
+DROP INDEX todo_items_due_idx;
+
+ALTER TABLE todo_items
+    DROP COLUMN assignee_id,
+    DROP COLUMN priority;
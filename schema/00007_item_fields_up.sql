@@ -0,0 +1,9 @@
+ALTER TABLE todo_items
+    ADD COLUMN priority    smallint NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 3),
+    ADD COLUMN assignee_id int REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX todo_items_due_idx ON todo_items (due) WHERE deleted_at IS NULL;
+
+CREATE INDEX todo_items_assignee_id_idx ON todo_items (assignee_id) WHERE deleted_at IS NULL;
+
+CREATE INDEX todo_items_labels_idx ON todo_items USING gin (labels);
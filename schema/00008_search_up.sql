@@ -0,0 +1,15 @@
+ALTER TABLE todo_lists
+    ADD COLUMN search tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+ALTER TABLE todo_items
+    ADD COLUMN search tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX todo_lists_search_idx ON todo_lists USING gin (search);
+
+CREATE INDEX todo_items_search_idx ON todo_items USING gin (search);
@@ -0,0 +1,12 @@
+CREATE TABLE smart_lists
+(
+    id      serial                                      not null unique,
+    user_id int references users (id) on delete cascade not null,
+    title   varchar(255)                                not null,
+    query   text                                        not null,
+    shared  boolean                                     not null default false
+);
+
+CREATE INDEX smart_lists_user_id_idx ON smart_lists (user_id);
+
+CREATE INDEX smart_lists_shared_idx ON smart_lists (shared) WHERE shared;
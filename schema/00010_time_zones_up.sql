@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN time_zone text not null default 'UTC';
@@ -0,0 +1,19 @@
+DROP TRIGGER users_lists_version ON users_lists;
+
+DROP FUNCTION bump_list_version();
+
+DROP TRIGGER lists_items_version ON lists_items;
+
+DROP FUNCTION bump_item_version();
+
+DROP TRIGGER todo_items_version ON todo_items;
+
+DROP TRIGGER todo_lists_version ON todo_lists;
+
+DROP FUNCTION bump_version();
+
+ALTER TABLE todo_items
+    DROP COLUMN version;
+
+ALTER TABLE todo_lists
+    DROP COLUMN version;
@@ -0,0 +1,59 @@
+ALTER TABLE todo_lists
+    ADD COLUMN version int not null default 1;
+
+ALTER TABLE todo_items
+    ADD COLUMN version int not null default 1;
+
+-- Every update of a list or an item moves it to the next version, unless the
+-- statement sets the version itself.
+CREATE FUNCTION bump_version() RETURNS trigger AS
+$$
+BEGIN
+    IF NEW.version = OLD.version THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_lists_version
+    BEFORE UPDATE
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER todo_items_version
+    BEFORE UPDATE
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+-- Positions live in the link tables, but they are part of what a client sees
+-- of a list or an item, so moving one is a new version too.
+CREATE FUNCTION bump_item_version() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE todo_items SET version = version + 1 WHERE id = NEW.item_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lists_items_version
+    AFTER UPDATE OF list_id, position
+    ON lists_items
+    FOR EACH ROW
+EXECUTE FUNCTION bump_item_version();
+
+CREATE FUNCTION bump_list_version() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE todo_lists SET version = version + 1 WHERE id = NEW.list_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_lists_version
+    AFTER UPDATE OF position
+    ON users_lists
+    FOR EACH ROW
+EXECUTE FUNCTION bump_list_version();
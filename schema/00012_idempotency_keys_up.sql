@@ -0,0 +1,14 @@
+CREATE TABLE idempotency_keys
+(
+    user_id      int references users (id) on delete cascade not null,
+    key          varchar(255)                                not null,
+    fingerprint  text                                        not null,
+    status       int,
+    headers      jsonb,
+    body         bytea,
+    created_at   timestamptz                                 not null default now(),
+    completed_at timestamptz,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
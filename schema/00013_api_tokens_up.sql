@@ -0,0 +1,10 @@
+CREATE TABLE api_tokens
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    name       varchar(100)                                not null,
+    token_hash bytea                                       not null unique,
+    created_at timestamptz                                 not null default now()
+);
+
+CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id, id);
@@ -0,0 +1,15 @@
+-- every new audit log entry is announced on the audit_log channel with the
+-- id of its list; the notifications are sent when the transaction commits
+CREATE FUNCTION audit_log_notify() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('audit_log', NEW.list_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_notify
+    AFTER INSERT
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_notify();
@@ -0,0 +1,29 @@
+DROP TRIGGER lists_items_moved_tombstone ON lists_items;
+
+DROP TRIGGER lists_items_tombstone ON lists_items;
+
+DROP FUNCTION bury_item();
+
+DROP TRIGGER users_lists_tombstone ON users_lists;
+
+DROP FUNCTION bury_list();
+
+DROP TABLE sync_tombstones;
+
+DROP TRIGGER todo_items_sync ON todo_items;
+
+DROP FUNCTION stamp_item_xid();
+
+DROP TRIGGER todo_lists_sync ON todo_lists;
+
+DROP FUNCTION stamp_list_xid();
+
+ALTER TABLE users_lists
+    DROP COLUMN created_xid;
+
+ALTER TABLE todo_items
+    DROP COLUMN sync_xid;
+
+ALTER TABLE todo_lists
+    DROP COLUMN visible_xid,
+    DROP COLUMN sync_xid;
@@ -0,0 +1,108 @@
+-- Sync reads changes by the transaction that made them: every list and item
+-- carries the id of the transaction that last changed it. A reader that takes
+-- the oldest transaction still running as its horizon has seen every change
+-- below it, whatever order the transactions committed in.
+ALTER TABLE todo_lists
+    ADD COLUMN sync_xid    bigint not null default pg_current_xact_id()::text::bigint,
+    ADD COLUMN visible_xid bigint not null default pg_current_xact_id()::text::bigint;
+
+ALTER TABLE todo_items
+    ADD COLUMN sync_xid bigint not null default pg_current_xact_id()::text::bigint;
+
+ALTER TABLE users_lists
+    ADD COLUMN created_xid bigint not null default pg_current_xact_id()::text::bigint;
+
+CREATE INDEX todo_lists_sync_xid_idx ON todo_lists (sync_xid);
+
+CREATE INDEX todo_lists_visible_xid_idx ON todo_lists (visible_xid);
+
+CREATE INDEX todo_items_sync_xid_idx ON todo_items (sync_xid);
+
+CREATE INDEX users_lists_created_xid_idx ON users_lists (user_id, created_xid);
+
+-- visible_xid changes only when a list goes to or comes back from the trash,
+-- which hides or shows all of its items.
+CREATE FUNCTION stamp_list_xid() RETURNS trigger AS
+$$
+BEGIN
+    NEW.sync_xid := pg_current_xact_id()::text::bigint;
+    IF NEW.deleted_at IS DISTINCT FROM OLD.deleted_at THEN
+        NEW.visible_xid := NEW.sync_xid;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_lists_sync
+    BEFORE UPDATE
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION stamp_list_xid();
+
+CREATE FUNCTION stamp_item_xid() RETURNS trigger AS
+$$
+BEGIN
+    NEW.sync_xid := pg_current_xact_id()::text::bigint;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_items_sync
+    BEFORE UPDATE
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION stamp_item_xid();
+
+-- Rows that are gone, or gone from a list, leave a tombstone behind. List
+-- tombstones are per user, item tombstones per list.
+CREATE TABLE sync_tombstones
+(
+    id         bigserial   not null unique,
+    entity     varchar(16) not null,
+    entity_id  int         not null,
+    user_id    int,
+    list_id    int,
+    sync_xid   bigint      not null default pg_current_xact_id()::text::bigint,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX sync_tombstones_user_idx ON sync_tombstones (user_id, sync_xid) WHERE user_id IS NOT NULL;
+
+CREATE INDEX sync_tombstones_list_idx ON sync_tombstones (list_id, sync_xid) WHERE list_id IS NOT NULL;
+
+CREATE INDEX sync_tombstones_created_at_idx ON sync_tombstones (created_at);
+
+CREATE FUNCTION bury_list() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO sync_tombstones (entity, entity_id, user_id) VALUES ('list', OLD.list_id, OLD.user_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_lists_tombstone
+    AFTER DELETE
+    ON users_lists
+    FOR EACH ROW
+EXECUTE FUNCTION bury_list();
+
+CREATE FUNCTION bury_item() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO sync_tombstones (entity, entity_id, list_id) VALUES ('item', OLD.item_id, OLD.list_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lists_items_tombstone
+    AFTER DELETE
+    ON lists_items
+    FOR EACH ROW
+EXECUTE FUNCTION bury_item();
+
+CREATE TRIGGER lists_items_moved_tombstone
+    AFTER UPDATE OF list_id
+    ON lists_items
+    FOR EACH ROW
+    WHEN (OLD.list_id IS DISTINCT FROM NEW.list_id)
+EXECUTE FUNCTION bury_item();
@@ -0,0 +1,7 @@
+DROP TRIGGER audit_log_webhooks ON audit_log;
+
+DROP FUNCTION enqueue_webhook_deliveries();
+
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhooks;
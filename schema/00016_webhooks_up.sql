@@ -0,0 +1,60 @@
+CREATE TABLE webhooks
+(
+    id          serial                                           not null unique,
+    user_id     int references users (id) on delete cascade      not null,
+    list_id     int references todo_lists (id) on delete cascade,
+    url         varchar(2048)                                    not null,
+    secret      varchar(64)                                      not null,
+    events      text[]                                           not null,
+    failures    int                                              not null default 0,
+    disabled_at timestamptz,
+    created_at  timestamptz                                      not null default now()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id, id);
+
+CREATE INDEX webhooks_list_id_idx ON webhooks (list_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              bigserial                                     not null unique,
+    webhook_id      int references webhooks (id) on delete cascade not null,
+    audit_id        bigint                                        not null,
+    event           varchar(64)                                   not null,
+    status          varchar(16)                                   not null default 'pending',
+    attempts        int                                           not null default 0,
+    next_attempt_at timestamptz                                   not null default now(),
+    response_status int,
+    error           text,
+    created_at      timestamptz                                   not null default now(),
+    delivered_at    timestamptz
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE INDEX webhook_deliveries_created_at_idx ON webhook_deliveries (created_at) WHERE status <> 'pending';
+
+-- every audit log entry is queued for the active webhooks subscribed to its
+-- event: those of its list, as long as their owner can still access it, and
+-- the workspace webhooks
+CREATE FUNCTION enqueue_webhook_deliveries() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO webhook_deliveries (webhook_id, audit_id, event)
+    SELECT w.id, NEW.id, NEW.entity || '.' || NEW.action
+    FROM webhooks w
+    WHERE w.disabled_at IS NULL
+      AND NEW.entity || '.' || NEW.action = ANY (w.events)
+      AND (w.list_id IS NULL OR w.list_id = NEW.list_id AND
+           EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = w.list_id AND ul.user_id = w.user_id));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_webhooks
+    AFTER INSERT
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION enqueue_webhook_deliveries();
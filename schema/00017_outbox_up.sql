@@ -0,0 +1,20 @@
+-- domain events, written in the transaction of the change they describe and
+-- relayed to the subscribers afterwards
+CREATE TABLE outbox
+(
+    id              bigserial                                 not null unique,
+    type            varchar(64)                               not null,
+    actor_id        int                                       not null,
+    list_id         int                                       not null,
+    entity_id       int                                       not null,
+    audit_id        bigint                                    not null,
+    changes         jsonb                                     not null,
+    attempts        int                                       not null default 0,
+    next_attempt_at timestamptz                               not null default now(),
+    created_at      timestamptz                               not null default now(),
+    published_at    timestamptz
+);
+
+CREATE INDEX outbox_due_idx ON outbox (next_attempt_at, id) WHERE published_at IS NULL;
+
+CREATE INDEX outbox_published_at_idx ON outbox (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Change streams read the audit log by the transaction that wrote an entry,
+-- like sync does: ids are taken when an entry is inserted but only become
+-- visible when its transaction commits, so reading by id would skip the
+-- entries of a transaction that commits after a later one.
+ALTER TABLE audit_log
+    ADD COLUMN xid bigint not null default pg_current_xact_id()::text::bigint;
+
+CREATE INDEX audit_log_xid_idx ON audit_log (xid, id);
+
+CREATE INDEX audit_log_list_xid_idx ON audit_log (list_id, xid, id);
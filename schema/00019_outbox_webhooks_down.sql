@@ -0,0 +1,19 @@
+CREATE FUNCTION enqueue_webhook_deliveries() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO webhook_deliveries (webhook_id, audit_id, event)
+    SELECT w.id, NEW.id, NEW.entity || '.' || NEW.action
+    FROM webhooks w
+    WHERE w.disabled_at IS NULL
+      AND NEW.entity || '.' || NEW.action = ANY (w.events)
+      AND (w.list_id IS NULL OR w.list_id = NEW.list_id AND
+           EXISTS (SELECT 1 FROM users_lists ul WHERE ul.list_id = w.list_id AND ul.user_id = w.user_id));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_webhooks
+    AFTER INSERT
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION enqueue_webhook_deliveries();
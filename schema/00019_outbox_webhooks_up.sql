@@ -0,0 +1,5 @@
+-- webhook deliveries are queued by the outbox relay, which hands every domain
+-- event to the webhooks subscribed to it
+DROP TRIGGER audit_log_webhooks ON audit_log;
+
+DROP FUNCTION enqueue_webhook_deliveries();